@@ -0,0 +1,603 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultAdminPrefix is the path prefix the admin API is served under when none is provided.
+const DefaultAdminPrefix = "/admin"
+
+// AdminOptions holds the options of the admin API.
+type AdminOptions struct {
+	// Addr is the address the admin API listens on. When empty, the admin
+	// API is served by the main server, under Prefix.
+	Addr string
+
+	// Prefix is the path prefix the admin API is served under.
+	Prefix string
+}
+
+// Validate checks if the AdminOptions struct satisfies the defined constraints.
+func (o AdminOptions) Validate() error {
+	if !strings.HasPrefix(o.Prefix, "/") {
+		return errors.New("prefix must start with a /")
+	}
+
+	if o.Prefix != "/" && strings.HasSuffix(o.Prefix, "/") {
+		return errors.New("prefix cannot end with a /")
+	}
+
+	// Served by the main server, a / prefix would shadow every other route
+	if o.Prefix == "/" && o.Addr == "" {
+		return errors.New("prefix cannot be / unless the admin API has its own address")
+	}
+
+	return nil
+}
+
+// Admin implements the admin API, allowing to inspect and change the
+// behaviour of a running server.
+type Admin struct {
+	defaults     Settings
+	settings     *RuntimeSettings
+	capture      *RequestCapture
+	stubs        *Stubs
+	tcpProxy     *TCPProxy
+	forwardProxy *ForwardProxy
+}
+
+// NewAdmin creates a new Admin instance, controlling the provided settings
+// and stubs, and inspecting the provided request capture.
+//
+// The settings held at creation time are the ones restored when the
+// settings are reset.
+func NewAdmin(settings *RuntimeSettings, capture *RequestCapture, stubs *Stubs) *Admin {
+	return &Admin{
+		defaults: settings.Get(),
+		settings: settings,
+		capture:  capture,
+		stubs:    stubs,
+	}
+}
+
+// WithTCPProxy makes the admin API control the toxics of the TCP proxy.
+func (a *Admin) WithTCPProxy(proxy *TCPProxy) *Admin {
+	a.tcpProxy = proxy
+
+	return a
+}
+
+// WithForwardProxy makes the admin API expose the requests received by the
+// forward proxy.
+func (a *Admin) WithForwardProxy(proxy *ForwardProxy) *Admin {
+	a.forwardProxy = proxy
+
+	return a
+}
+
+// RegisterAdminHandlers registers the admin API's handlers on the router,
+// under the provided path prefix.
+func RegisterAdminHandlers(router EchoRouter, admin *Admin, prefix string) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	router.GET(prefix+"/settings", admin.GetSettings)
+	router.PUT(prefix+"/settings", admin.PutSettings)
+	router.PATCH(prefix+"/settings", admin.PatchSettings)
+	router.DELETE(prefix+"/settings", admin.DeleteSettings)
+
+	router.GET(prefix+"/schedule", admin.GetSchedule)
+	router.PUT(prefix+"/schedule", admin.PutSchedule)
+	router.DELETE(prefix+"/schedule", admin.DeleteSchedule)
+	router.POST(prefix+"/schedule/start", admin.PostScheduleStart)
+
+	router.GET(prefix+"/requests", admin.GetRequests)
+	router.DELETE(prefix+"/requests", admin.DeleteRequests)
+	router.POST(prefix+"/requests/verify", admin.PostRequestsVerify)
+	router.GET(prefix+"/har", admin.GetHar)
+	router.GET(prefix+"/requests/:id", admin.GetRequestsId)
+
+	router.GET(prefix+"/stubs", admin.GetStubs)
+	router.POST(prefix+"/stubs", admin.PostStubs)
+	router.DELETE(prefix+"/stubs", admin.DeleteStubs)
+	router.GET(prefix+"/stubs/:id", admin.GetStubsId)
+	router.DELETE(prefix+"/stubs/:id", admin.DeleteStubsId)
+
+	router.GET(prefix+"/scenarios", admin.GetScenarios)
+	router.DELETE(prefix+"/scenarios", admin.DeleteScenarios)
+	router.PUT(prefix+"/scenarios/:name", admin.PutScenariosName)
+
+	router.GET(prefix+"/tcp-proxy", admin.GetTCPProxy)
+	router.PUT(prefix+"/tcp-proxy/toxics", admin.PutTCPProxyToxics)
+	router.DELETE(prefix+"/tcp-proxy/toxics", admin.DeleteTCPProxyToxics)
+
+	router.GET(prefix+"/forward-proxy/requests", admin.GetForwardProxyRequests)
+	router.DELETE(prefix+"/forward-proxy/requests", admin.DeleteForwardProxyRequests)
+}
+
+// GetSettings is a handler responding with the current runtime settings.
+func (a *Admin) GetSettings(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, a.settings.Get())
+}
+
+// PutSettings is a handler replacing the runtime settings with the ones held
+// by the request body. Omitted fields take their default value.
+func (a *Admin) PutSettings(ctx echo.Context) error {
+	return a.updateSettings(ctx, "PutSettings", DefaultSettings())
+}
+
+// PatchSettings is a handler updating the runtime settings with the fields
+// held by the request body. Omitted fields are left untouched.
+func (a *Admin) PatchSettings(ctx echo.Context) error {
+	return a.updateSettings(ctx, "PatchSettings", a.settings.Get())
+}
+
+// DeleteSettings is a handler restoring the settings the server started with.
+func (a *Admin) DeleteSettings(ctx echo.Context) error {
+	if err := a.settings.Set(a.defaults); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, a.settings.Get())
+}
+
+// updateSettings decodes the request body on top of the base settings, and
+// applies the result.
+func (a *Admin) updateSettings(ctx echo.Context, handler string, base Settings) error {
+	decoder := json.NewDecoder(ctx.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	settings := base
+	if err := decoder.Decode(&settings); err != nil {
+		slog.Error(
+			"failed decoding settings",
+			"handler", handler,
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	if err := a.settings.Set(settings); err != nil {
+		slog.Error(
+			"failed applying settings",
+			"handler", handler,
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, a.settings.Get())
+}
+
+// GetSchedule is a handler responding with the progress of the applied schedule.
+func (a *Admin) GetSchedule(ctx echo.Context) error {
+	status, ok := a.settings.ScheduleStatus()
+	if !ok {
+		return ctx.String(http.StatusNotFound, ErrNoSchedule.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, status)
+}
+
+// PutSchedule is a handler applying the schedule held by the request body,
+// and starting a run of it.
+func (a *Admin) PutSchedule(ctx echo.Context) error {
+	decoder := json.NewDecoder(ctx.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	var schedule Schedule
+	if err := decoder.Decode(&schedule); err != nil {
+		slog.Error(
+			"failed decoding schedule",
+			"handler", "PutSchedule",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	if err := a.settings.SetSchedule(schedule); err != nil {
+		slog.Error(
+			"failed applying schedule",
+			"handler", "PutSchedule",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return a.GetSchedule(ctx)
+}
+
+// DeleteSchedule is a handler removing the applied schedule.
+func (a *Admin) DeleteSchedule(ctx echo.Context) error {
+	a.settings.ClearSchedule()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// PostScheduleStart is a handler starting a new run of the applied
+// schedule, marking the start of a test run.
+func (a *Admin) PostScheduleStart(ctx echo.Context) error {
+	if err := a.settings.StartSchedule(); err != nil {
+		return ctx.String(http.StatusNotFound, err.Error())
+	}
+
+	return a.GetSchedule(ctx)
+}
+
+// GetRequests is a handler responding with the captured requests, from the
+// oldest to the most recent.
+//
+// The requests can be filtered using the method, path and header query
+// parameters, as described by CaptureFilter, and the limit query parameter
+// restricts the response to the most recent ones.
+func (a *Admin) GetRequests(ctx echo.Context) error {
+	requests, err := a.listRequests(ctx, "GetRequests")
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, requests)
+}
+
+// GetRequestsId is a handler responding with a single captured request.
+func (a *Admin) GetRequestsId(ctx echo.Context) error {
+	id, err := parseAdminID(ctx, "GetRequestsId")
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	req, ok := a.capture.Get(id)
+	if !ok {
+		return ctx.String(http.StatusNotFound, "request not found")
+	}
+
+	return ctx.JSON(http.StatusOK, req)
+}
+
+// DeleteRequests is a handler clearing the captured requests.
+func (a *Admin) DeleteRequests(ctx echo.Context) error {
+	a.capture.Clear()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Verification asserts how many captured requests satisfy a matcher.
+type Verification struct {
+	// Request describes the requests to count.
+	Request RequestMatcher `json:"request"`
+
+	// Count is the expected number of matching requests. When omitted, the
+	// verification only reports the count.
+	Count *int `json:"count,omitempty"`
+}
+
+// VerificationResult holds the outcome of a Verification.
+type VerificationResult struct {
+	Request  RequestMatcher `json:"request"`
+	Count    int            `json:"count"`
+	Expected *int           `json:"expected,omitempty"`
+	Verified bool           `json:"verified"`
+
+	// Incomplete is true when captured requests were evicted, in which case
+	// Count only counts the requests still captured, and expected counts
+	// cannot be verified.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// PostRequestsVerify is a handler counting the captured requests satisfying
+// each of the verifications held by the request body, as a JSON array.
+//
+// It responds with the results of the verifications, with a 200 if all of
+// them are verified, and a 417 otherwise.
+//
+// Request bodies are matched against their captured preview only. Once
+// captured requests were evicted, the counts are incomplete, and expected
+// counts fail verification. It responds with a 404 if the capture is disabled.
+func (a *Admin) PostRequestsVerify(ctx echo.Context) error {
+	if !a.capture.Enabled() {
+		return ctx.String(http.StatusNotFound, ErrCaptureDisabled.Error())
+	}
+
+	var verifications []Verification
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&verifications); err != nil {
+		slog.Error(
+			"failed decoding verifications",
+			"handler", "PostRequestsVerify",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	// Evictions are counted after listing, so that the ones racing with it
+	// mark the counts as incomplete.
+	requests := a.capture.List(CaptureFilter{})
+	incomplete := a.capture.Evicted() > 0
+
+	status := http.StatusOK
+	results := make([]VerificationResult, 0, len(verifications))
+	for i := range verifications {
+		verification := &verifications[i]
+		if err := verification.Request.Compile(); err != nil {
+			slog.Error(
+				"failed compiling request matcher",
+				"handler", "PostRequestsVerify",
+				"error_message", err.Error(),
+			)
+
+			return ctx.String(http.StatusBadRequest, err.Error())
+		}
+
+		result := VerificationResult{
+			Request:    verification.Request,
+			Expected:   verification.Count,
+			Incomplete: incomplete,
+		}
+
+		for _, req := range requests {
+			if verification.Request.Match(req) {
+				result.Count++
+			}
+		}
+
+		result.Verified = verification.Count == nil || (!incomplete && *verification.Count == result.Count)
+		if !result.Verified {
+			status = http.StatusExpectationFailed
+		}
+
+		results = append(results, result)
+	}
+
+	return ctx.JSON(status, results)
+}
+
+// GetStubs is a handler responding with the registered stubs, in matching order.
+func (a *Admin) GetStubs(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, a.stubs.List())
+}
+
+// PostStubs is a handler registering the stub held by the request body.
+func (a *Admin) PostStubs(ctx echo.Context) error {
+	decoder := json.NewDecoder(ctx.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	var stub Stub
+	if err := decoder.Decode(&stub); err != nil {
+		slog.Error(
+			"failed decoding stub",
+			"handler", "PostStubs",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	stub, err := a.stubs.Add(stub)
+	if err != nil {
+		slog.Error(
+			"failed registering stub",
+			"handler", "PostStubs",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return ctx.JSON(http.StatusCreated, stub)
+}
+
+// DeleteStubs is a handler removing every stub and scenario.
+func (a *Admin) DeleteStubs(ctx echo.Context) error {
+	a.stubs.Clear()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetStubsId is a handler responding with a single stub.
+func (a *Admin) GetStubsId(ctx echo.Context) error {
+	id, err := parseAdminID(ctx, "GetStubsId")
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	stub, ok := a.stubs.Get(id)
+	if !ok {
+		return ctx.String(http.StatusNotFound, ErrStubNotFound.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, stub)
+}
+
+// DeleteStubsId is a handler removing a single stub.
+func (a *Admin) DeleteStubsId(ctx echo.Context) error {
+	id, err := parseAdminID(ctx, "DeleteStubsId")
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	if err = a.stubs.Delete(id); err != nil {
+		return ctx.String(http.StatusNotFound, err.Error())
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetScenarios is a handler responding with the state of every scenario, indexed by name.
+func (a *Admin) GetScenarios(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, a.stubs.Scenarios())
+}
+
+// DeleteScenarios is a handler setting every scenario back to its started state.
+func (a *Admin) DeleteScenarios(ctx echo.Context) error {
+	a.stubs.ResetScenarios()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// PutScenariosName is a handler setting the state of a scenario to the one
+// held by the request body, as a {"state": "..."} JSON object.
+func (a *Admin) PutScenariosName(ctx echo.Context) error {
+	var body struct {
+		State string `json:"state"`
+	}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&body); err != nil || body.State == "" {
+		slog.Error(
+			"failed decoding scenario state",
+			"handler", "PutScenariosName",
+		)
+
+		return ctx.String(http.StatusBadRequest, "body must be a JSON object holding a non-empty state")
+	}
+
+	a.stubs.SetScenario(ctx.Param("name"), body.State)
+
+	return ctx.JSON(http.StatusOK, a.stubs.Scenarios())
+}
+
+// GetTCPProxy is a handler responding with the status of the TCP proxy,
+// toxics included.
+func (a *Admin) GetTCPProxy(ctx echo.Context) error {
+	if a.tcpProxy == nil {
+		return ctx.String(http.StatusNotFound, ErrTCPProxyDisabled.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, a.tcpProxy.Status())
+}
+
+// PutTCPProxyToxics is a handler replacing the toxics of the TCP proxy with
+// the ones held by the request body. Omitted fields apply no degradation.
+func (a *Admin) PutTCPProxyToxics(ctx echo.Context) error {
+	if a.tcpProxy == nil {
+		return ctx.String(http.StatusNotFound, ErrTCPProxyDisabled.Error())
+	}
+
+	decoder := json.NewDecoder(ctx.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	var toxics TCPToxics
+	if err := decoder.Decode(&toxics); err != nil {
+		slog.Error(
+			"failed decoding TCP proxy toxics",
+			"handler", "PutTCPProxyToxics",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	if err := a.tcpProxy.SetToxics(toxics); err != nil {
+		slog.Error(
+			"failed applying TCP proxy toxics",
+			"handler", "PutTCPProxyToxics",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, a.tcpProxy.Status())
+}
+
+// DeleteTCPProxyToxics is a handler removing the toxics of the TCP proxy.
+func (a *Admin) DeleteTCPProxyToxics(ctx echo.Context) error {
+	if a.tcpProxy == nil {
+		return ctx.String(http.StatusNotFound, ErrTCPProxyDisabled.Error())
+	}
+
+	if err := a.tcpProxy.SetToxics(TCPToxics{}); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, a.tcpProxy.Status())
+}
+
+// GetForwardProxyRequests is a handler responding with the requests received
+// by the forward proxy, from the oldest to the most recent.
+func (a *Admin) GetForwardProxyRequests(ctx echo.Context) error {
+	if a.forwardProxy == nil {
+		return ctx.String(http.StatusNotFound, ErrForwardProxyDisabled.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, a.forwardProxy.Records())
+}
+
+// DeleteForwardProxyRequests is a handler removing the requests recorded by
+// the forward proxy.
+func (a *Admin) DeleteForwardProxyRequests(ctx echo.Context) error {
+	if a.forwardProxy == nil {
+		return ctx.String(http.StatusNotFound, ErrForwardProxyDisabled.Error())
+	}
+
+	a.forwardProxy.ClearRecords()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetHar is a handler responding with the captured requests as an HTTP
+// Archive, filtered as GetRequests does.
+func (a *Admin) GetHar(ctx echo.Context) error {
+	requests, err := a.listRequests(ctx, "GetHar")
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="lhotse.har"`)
+
+	return ctx.JSON(http.StatusOK, NewHAR(requests))
+}
+
+// listRequests returns the captured requests selected by the method, path,
+// header and limit query parameters.
+func (a *Admin) listRequests(ctx echo.Context, handler string) ([]CapturedRequest, error) {
+	filter := CaptureFilter{
+		Method:  ctx.QueryParam("method"),
+		Path:    ctx.QueryParam("path"),
+		Headers: ctx.QueryParams()["header"],
+	}
+
+	requests := a.capture.List(filter)
+
+	if rawLimit := ctx.QueryParam("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 0 {
+			slog.Error(
+				"failed parsing limit",
+				"handler", handler,
+				"limit", rawLimit,
+			)
+
+			return nil, errors.New("limit must be a positive integer")
+		}
+
+		requests = requests[max(len(requests)-limit, 0):]
+	}
+
+	return requests, nil
+}
+
+// parseAdminID parses the id path parameter of an admin API request.
+func parseAdminID(ctx echo.Context, handler string) (int64, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		slog.Error(
+			"failed parsing id",
+			"handler", handler,
+			"error_message", err.Error(),
+		)
+
+		return 0, err
+	}
+
+	return id, nil
+}
@@ -0,0 +1,645 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminSettingsHandlers(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()), DefaultAdminPrefix)
+
+	steps := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "getting the settings should return the defaults",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"latency":"0s","error_rate":0,"error_status":500,"bandwidth":"0b","disabled_routes":[]}`,
+		},
+		{
+			name:       "putting settings should replace them",
+			method:     http.MethodPut,
+			body:       `{"latency":"10ms-20ms","bandwidth":"1kb","disabled_routes":["/data/{size}"]}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"latency":"10ms-20ms","error_rate":0,"error_status":500,"bandwidth":"1024b","disabled_routes":["/data/{size}"]}`,
+		},
+		{
+			name:       "patching settings should only update the provided fields",
+			method:     http.MethodPatch,
+			body:       `{"error_rate":0.5,"error_status":503}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"latency":"10ms-20ms","error_rate":0.5,"error_status":503,"bandwidth":"1024b","disabled_routes":["/data/{size}"]}`,
+		},
+		{
+			name:       "invalid settings should fail",
+			method:     http.MethodPatch,
+			body:       `{"error_rate":2}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown settings should fail",
+			method:     http.MethodPatch,
+			body:       `{"unknown":true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "deleting the settings should restore the defaults",
+			method:     http.MethodDelete,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"latency":"0s","error_rate":0,"error_status":500,"bandwidth":"0b","disabled_routes":[]}`,
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, "/admin/settings", strings.NewReader(step.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, step.wantStatus, rec.Code, step.name)
+		if step.wantBody != "" {
+			assert.JSONEq(t, step.wantBody, rec.Body.String(), step.name)
+		}
+	}
+}
+
+func TestAdminRequestsHandlers(t *testing.T) {
+	t.Parallel()
+
+	capture := NewRequestCapture(CaptureOptions{Size: 10})
+	capture.Add(CapturedRequest{Method: http.MethodGet, Path: "/data/1kb"})
+	capture.Add(CapturedRequest{Method: http.MethodPost, Path: "/store/users", Headers: http.Header{"X-Tenant": []string{"a"}}})
+	capture.Add(CapturedRequest{Method: http.MethodGet, Path: "/data/2kb"})
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), capture, NewStubs()), DefaultAdminPrefix)
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantIDs    []int64
+	}{
+		{
+			name:       "listing requests should return all of them",
+			method:     http.MethodGet,
+			target:     "/admin/requests",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{1, 2, 3},
+		},
+		{
+			name:       "listing requests by path should filter them",
+			method:     http.MethodGet,
+			target:     "/admin/requests?path=/data/*",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{1, 3},
+		},
+		{
+			name:       "listing requests by header should filter them",
+			method:     http.MethodGet,
+			target:     "/admin/requests?header=X-Tenant:a",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{2},
+		},
+		{
+			name:       "listing requests with a limit should return the most recent ones",
+			method:     http.MethodGet,
+			target:     "/admin/requests?limit=2",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{2, 3},
+		},
+		{
+			name:       "listing requests with an invalid limit should fail",
+			method:     http.MethodGet,
+			target:     "/admin/requests?limit=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getting a request should return it",
+			method:     http.MethodGet,
+			target:     "/admin/requests/2",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{2},
+		},
+		{
+			name:       "getting an unknown request should fail",
+			method:     http.MethodGet,
+			target:     "/admin/requests/42",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "clearing the requests should remove them",
+			method:     http.MethodDelete,
+			target:     "/admin/requests",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "listing cleared requests should return none",
+			method:     http.MethodGet,
+			target:     "/admin/requests",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{},
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.target, nil)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, step.wantStatus, rec.Code, step.name)
+		if step.wantIDs == nil {
+			continue
+		}
+
+		var requests []CapturedRequest
+		if strings.HasPrefix(rec.Body.String(), "[") {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &requests), step.name)
+		} else {
+			var single CapturedRequest
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &single), step.name)
+			requests = append(requests, single)
+		}
+
+		ids := make([]int64, 0, len(requests))
+		for _, r := range requests {
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, step.wantIDs, ids, step.name)
+	}
+}
+
+func TestAdminRequestsVerifyHandler(t *testing.T) {
+	t.Parallel()
+
+	capture := NewRequestCapture(CaptureOptions{Size: 10})
+	for i := 0; i < 3; i++ {
+		capture.Add(CapturedRequest{Method: http.MethodPost, Path: "/upload", Headers: http.Header{"X-Tenant": []string{"a"}}})
+	}
+	capture.Add(CapturedRequest{Method: http.MethodGet, Path: "/upload"})
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), capture, NewStubs()), DefaultAdminPrefix)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCounts []int
+	}{
+		{
+			name:       "counting requests should report the counts",
+			body:       `[{"request":{"method":"POST","path":"/upload","headers":{"X-Tenant":"a"}}},{"request":{"path":"/up.*"}}]`,
+			wantStatus: http.StatusOK,
+			wantCounts: []int{3, 4},
+		},
+		{
+			name:       "met expectations should be verified",
+			body:       `[{"request":{"method":"GET"},"count":1}]`,
+			wantStatus: http.StatusOK,
+			wantCounts: []int{1},
+		},
+		{
+			name:       "unmet expectations should fail",
+			body:       `[{"request":{"method":"POST"},"count":100}]`,
+			wantStatus: http.StatusExpectationFailed,
+			wantCounts: []int{3},
+		},
+		{
+			name:       "invalid path pattern should fail",
+			body:       `[{"request":{"path":"("}}]`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/requests/verify", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantCounts == nil {
+				return
+			}
+
+			var results []VerificationResult
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+
+			counts := make([]int, 0, len(results))
+			for _, result := range results {
+				counts = append(counts, result.Count)
+			}
+			assert.Equal(t, tt.wantCounts, counts)
+		})
+	}
+}
+
+func TestAdminStubsHandlers(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()), DefaultAdminPrefix)
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "registering a stub should assign it an identifier",
+			method:     http.MethodPost,
+			target:     "/admin/stubs",
+			body:       `{"request":{"path":"/users"},"response":{"status":201,"size":"1kb"},"scenario":"signup","new_state":"done"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":1,"priority":0,"request":{"path":"/users"},"response":{"status":201,"latency":"0s","size":"1024b"},"scenario":"signup","new_state":"done"}`,
+		},
+		{
+			name:       "registering an invalid stub should fail",
+			method:     http.MethodPost,
+			target:     "/admin/stubs",
+			body:       `{"request":{"path":"("}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "getting a stub should return it",
+			method:     http.MethodGet,
+			target:     "/admin/stubs/1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "listing scenarios should return their states",
+			method:     http.MethodGet,
+			target:     "/admin/scenarios",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"signup":"Started"}`,
+		},
+		{
+			name:       "setting a scenario state should change it",
+			method:     http.MethodPut,
+			target:     "/admin/scenarios/signup",
+			body:       `{"state":"done"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"signup":"done"}`,
+		},
+		{
+			name:       "resetting scenarios should restore their started state",
+			method:     http.MethodDelete,
+			target:     "/admin/scenarios",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "deleting a stub should remove it",
+			method:     http.MethodDelete,
+			target:     "/admin/stubs/1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "getting a deleted stub should fail",
+			method:     http.MethodGet,
+			target:     "/admin/stubs/1",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "listing stubs should return the remaining ones",
+			method:     http.MethodGet,
+			target:     "/admin/stubs",
+			wantStatus: http.StatusOK,
+			wantBody:   `[]`,
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, step.wantStatus, rec.Code, step.name)
+		if step.wantBody != "" {
+			assert.JSONEq(t, step.wantBody, rec.Body.String(), step.name)
+		}
+	}
+}
+
+func TestAdminHarHandler(t *testing.T) {
+	t.Parallel()
+
+	capture := NewRequestCapture(CaptureOptions{Size: 10})
+	capture.Add(CapturedRequest{Method: http.MethodGet, Path: "/data/1kb", Status: http.StatusOK})
+	capture.Add(CapturedRequest{Method: http.MethodPost, Path: "/store/users", Status: http.StatusCreated})
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), capture, NewStubs()), DefaultAdminPrefix)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/har?method=POST", nil)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "lhotse.har")
+
+	var har HAR
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &har))
+	require.Len(t, har.Log.Entries, 1)
+	assert.Equal(t, http.StatusCreated, har.Log.Entries[0].Response.Status)
+}
+
+func TestAdminRequestsVerifyHandler_IncompleteCapture(t *testing.T) {
+	t.Parallel()
+
+	disabled := echo.New()
+	RegisterAdminHandlers(disabled, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()), DefaultAdminPrefix)
+
+	rec := httptest.NewRecorder()
+	disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/requests/verify", strings.NewReader(`[{"request":{},"count":0}]`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "verifying without capture should fail")
+
+	capture := NewRequestCapture(CaptureOptions{Size: 2})
+	for i := 0; i < 3; i++ {
+		capture.Add(CapturedRequest{Method: http.MethodGet, Path: "/upload"})
+	}
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), capture, NewStubs()), DefaultAdminPrefix)
+
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantVerified bool
+	}{
+		{
+			name:         "counting requests should report incomplete counts",
+			body:         `[{"request":{"method":"GET"}}]`,
+			wantStatus:   http.StatusOK,
+			wantVerified: true,
+		},
+		{
+			name:       "expectations should fail once requests were evicted",
+			body:       `[{"request":{"method":"GET"},"count":2}]`,
+			wantStatus: http.StatusExpectationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/requests/verify", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var results []VerificationResult
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+			require.Len(t, results, 1)
+			assert.Equal(t, 2, results[0].Count)
+			assert.True(t, results[0].Incomplete)
+			assert.Equal(t, tt.wantVerified, results[0].Verified)
+		})
+	}
+}
+
+func TestAdminScheduleHandlers(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()), DefaultAdminPrefix)
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantStage  int
+	}{
+		{
+			name:       "getting an unset schedule should fail",
+			method:     http.MethodGet,
+			target:     "/admin/schedule",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "starting an unset schedule should fail",
+			method:     http.MethodPost,
+			target:     "/admin/schedule/start",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid schedule should fail",
+			method:     http.MethodPut,
+			target:     "/admin/schedule",
+			body:       `{"stages":[{"duration":"1m","error_rate":2}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "putting a schedule should apply and start it",
+			method:     http.MethodPut,
+			target:     "/admin/schedule",
+			body:       `{"stages":[{"duration":"1m","latency":"50ms"},{"duration":"1m","latency":"800ms","ramp":true}]}`,
+			wantStatus: http.StatusOK,
+			wantStage:  0,
+		},
+		{
+			name:       "getting the schedule should return its progress",
+			method:     http.MethodGet,
+			target:     "/admin/schedule",
+			wantStatus: http.StatusOK,
+			wantStage:  0,
+		},
+		{
+			name:       "starting the schedule should restart it",
+			method:     http.MethodPost,
+			target:     "/admin/schedule/start",
+			wantStatus: http.StatusOK,
+			wantStage:  0,
+		},
+		{
+			name:       "deleting the schedule should remove it",
+			method:     http.MethodDelete,
+			target:     "/admin/schedule",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "getting a deleted schedule should fail",
+			method:     http.MethodGet,
+			target:     "/admin/schedule",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, step.wantStatus, rec.Code, step.name)
+		if step.wantStatus == http.StatusOK {
+			var status ScheduleStatus
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status), step.name)
+			assert.True(t, status.Active, step.name)
+			assert.Equal(t, step.wantStage, status.Stage, step.name)
+			assert.Equal(t, Latency{LowerBound: 50 * time.Millisecond}, status.Settings.Latency, step.name)
+			assert.Len(t, status.Schedule.Stages, 2, step.name)
+		}
+	}
+}
+
+func TestAdminTCPProxyHandlers(t *testing.T) {
+	t.Parallel()
+
+	disabled := echo.New()
+	RegisterAdminHandlers(disabled, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()), DefaultAdminPrefix)
+
+	rec := httptest.NewRecorder()
+	disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/tcp-proxy", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "disabled TCP proxy should not be found")
+
+	proxy := NewTCPProxy(TCPProxyOptions{Addr: "127.0.0.1:6380", Upstream: "127.0.0.1:6379"})
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()).WithTCPProxy(proxy), DefaultAdminPrefix)
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantToxics TCPToxics
+	}{
+		{
+			name:       "getting the TCP proxy should return no toxics",
+			method:     http.MethodGet,
+			target:     "/admin/tcp-proxy",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid toxics should fail",
+			method:     http.MethodPut,
+			target:     "/admin/tcp-proxy/toxics",
+			body:       `{"reset_rate":2}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown toxics should fail",
+			method:     http.MethodPut,
+			target:     "/admin/tcp-proxy/toxics",
+			body:       `{"timeout":"1s"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "putting toxics should apply them",
+			method:     http.MethodPut,
+			target:     "/admin/tcp-proxy/toxics",
+			body:       `{"downstream":{"latency":"100ms","bandwidth":"64kb"},"reset_rate":0.1}`,
+			wantStatus: http.StatusOK,
+			wantToxics: TCPToxics{
+				Downstream: TCPDirectionToxics{Latency: Duration(100 * time.Millisecond), Bandwidth: 64 * 1024},
+				ResetRate:  0.1,
+			},
+		},
+		{
+			name:       "getting the TCP proxy should return its toxics",
+			method:     http.MethodGet,
+			target:     "/admin/tcp-proxy",
+			wantStatus: http.StatusOK,
+			wantToxics: TCPToxics{
+				Downstream: TCPDirectionToxics{Latency: Duration(100 * time.Millisecond), Bandwidth: 64 * 1024},
+				ResetRate:  0.1,
+			},
+		},
+		{
+			name:       "deleting the toxics should remove them",
+			method:     http.MethodDelete,
+			target:     "/admin/tcp-proxy/toxics",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, step.wantStatus, rec.Code, step.name)
+		if step.wantStatus == http.StatusOK {
+			var status TCPProxyStatus
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status), step.name)
+			assert.Equal(t, "127.0.0.1:6379", status.Upstream, step.name)
+			assert.Equal(t, step.wantToxics, status.Toxics, step.name)
+		}
+	}
+}
+
+func TestAdminForwardProxyHandlers(t *testing.T) {
+	t.Parallel()
+
+	disabled := echo.New()
+	RegisterAdminHandlers(disabled, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()), DefaultAdminPrefix)
+
+	rec := httptest.NewRecorder()
+	disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/forward-proxy/requests", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "disabled forward proxy should not be found")
+
+	proxy := NewForwardProxy(ForwardProxyOptions{Addr: "127.0.0.1:3128"})
+	proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	e := echo.New()
+	RegisterAdminHandlers(e, NewAdmin(NewRuntimeSettings(DefaultSettings()), NewRequestCapture(CaptureOptions{}), NewStubs()).WithForwardProxy(proxy), DefaultAdminPrefix)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/forward-proxy/requests", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var records []ForwardProxyRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, http.MethodGet, records[0].Method)
+	assert.Equal(t, http.StatusBadRequest, records[0].Status)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/forward-proxy/requests", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, proxy.Records())
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuthRealm is the realm advertised in the authentication challenges.
+const AuthRealm = "lhotse"
+
+// DefaultAPIKeyHeader is the header holding the API key when none is specified.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// DefaultAPIKeyQuery is the query parameter holding the API key when none is specified.
+const DefaultAPIKeyQuery = "api_key"
+
+// ErrUnsupportedDigestAlgorithm is returned when a digest algorithm is not supported.
+var ErrUnsupportedDigestAlgorithm = errors.New("unsupported digest algorithm")
+
+// ErrUnsupportedDigestQop is returned when a digest quality of protection is not supported.
+var ErrUnsupportedDigestQop = errors.New("unsupported digest quality of protection")
+
+// authenticator verifies the credentials of a request.
+//
+// It returns the identity the request was authenticated as, and whether
+// authentication succeeded. On failure, it returns the challenge to send
+// back to the client in the WWW-Authenticate header.
+type authenticator func(req *http.Request) (identity string, challenge string, ok bool)
+
+// GetAuthBasicUserPassword is a handler requiring HTTP Basic authentication.
+//
+// It responds with a 200 if the request's credentials match the {user} and
+// {password} parameters, and a 401 with a Basic challenge otherwise.
+func (s *ServerImpl) GetAuthBasicUserPassword(
+	ctx echo.Context,
+	user AuthUser,
+	password AuthPassword,
+	params GetAuthBasicUserPasswordParams,
+) error {
+	return s.authenticate(ctx, "basic", params.Latency, func(req *http.Request) (string, string, bool) {
+		challenge := fmt.Sprintf(`Basic realm=%q`, AuthRealm)
+
+		gotUser, gotPassword, ok := req.BasicAuth()
+		if !ok || !secureEqual(gotUser, user) || !secureEqual(gotPassword, password) {
+			return "", challenge, false
+		}
+
+		return gotUser, "", true
+	})
+}
+
+// GetAuthBearer is a handler requiring a bearer token.
+//
+// It responds with a 200 if the request holds a bearer token matching the
+// token parameter, or any bearer token if the parameter is omitted, and a 401
+// with a Bearer challenge otherwise.
+func (s *ServerImpl) GetAuthBearer(ctx echo.Context, params GetAuthBearerParams) error {
+	return s.authenticate(ctx, "bearer", params.Latency, func(req *http.Request) (string, string, bool) {
+		challenge := fmt.Sprintf(`Bearer realm=%q`, AuthRealm)
+
+		authorization := req.Header.Get(echo.HeaderAuthorization)
+		scheme, token, found := strings.Cut(authorization, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return "", challenge, false
+		}
+
+		if params.Token != nil && !secureEqual(token, *params.Token) {
+			return "", challenge + `, error="invalid_token"`, false
+		}
+
+		return token, "", true
+	})
+}
+
+// GetAuthApikeyKey is a handler requiring an API key.
+//
+// It responds with a 200 if the request holds the {key} API key, either in
+// the header or in the query parameter named by the parameters, and a 401
+// otherwise.
+func (s *ServerImpl) GetAuthApikeyKey(ctx echo.Context, key string, params GetAuthApikeyKeyParams) error {
+	headerName := DefaultAPIKeyHeader
+	if params.Header != nil {
+		headerName = *params.Header
+	}
+
+	queryName := DefaultAPIKeyQuery
+	if params.Query != nil {
+		queryName = *params.Query
+	}
+
+	return s.authenticate(ctx, "apikey", params.Latency, func(req *http.Request) (string, string, bool) {
+		challenge := fmt.Sprintf(`APIKey realm=%q, header=%q, query=%q`, AuthRealm, headerName, queryName)
+
+		for _, candidate := range []string{req.Header.Get(headerName), req.URL.Query().Get(queryName)} {
+			if candidate != "" && secureEqual(candidate, key) {
+				return candidate, "", true
+			}
+		}
+
+		return "", challenge, false
+	})
+}
+
+// GetAuthDigestUserPassword is a handler requiring HTTP Digest authentication.
+//
+// It responds with a 200 if the request's digest matches the {user} and
+// {password} parameters, and a 401 with a Digest challenge otherwise. The
+// MD5 and SHA-256 algorithms are supported, as well as the auth and auth-int
+// qualities of protection.
+//
+// Nonces are not tracked: any nonce is accepted as long as the digest computed
+// with it is valid.
+func (s *ServerImpl) GetAuthDigestUserPassword(
+	ctx echo.Context,
+	user AuthUser,
+	password AuthPassword,
+	params GetAuthDigestUserPasswordParams,
+) error {
+	digest := digestAuth{
+		user:      user,
+		password:  password,
+		algorithm: "MD5",
+		qop:       "auth",
+	}
+
+	if params.Algorithm != nil {
+		digest.algorithm = strings.ToUpper(*params.Algorithm)
+	}
+
+	if params.Qop != nil {
+		digest.qop = strings.ToLower(*params.Qop)
+	}
+
+	if err := digest.Validate(); err != nil {
+		slog.Error(
+			"failed validating digest parameters",
+			"handler", "GetAuthDigestUserPassword",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return s.authenticate(ctx, "digest", params.Latency, digest.authenticate)
+}
+
+// authenticate waits for the requested latency, and authenticates the
+// request using the provided authenticator.
+func (s *ServerImpl) authenticate(ctx echo.Context, scheme string, latencyParam *string, auth authenticator) error {
+	if latencyParam != nil {
+		latency, err := ParseValidLatency(*latencyParam)
+		if err != nil {
+			slog.Error(
+				"failed parsing latency duration",
+				"handler", "authenticate",
+				"duration", *latencyParam,
+				"error_message", err.Error(),
+			)
+
+			return ctx.String(http.StatusBadRequest, err.Error())
+		}
+
+		latency.Wait()
+	}
+
+	identity, challenge, ok := auth(ctx.Request())
+	if !ok {
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+		return ctx.JSON(http.StatusUnauthorized, Identity{Scheme: scheme})
+	}
+
+	return ctx.JSON(http.StatusOK, Identity{
+		Authenticated: true,
+		Scheme:        scheme,
+		Identity:      identity,
+	})
+}
+
+// digestAuth implements HTTP Digest authentication as described in RFC 7616.
+type digestAuth struct {
+	user      string
+	password  string
+	algorithm string
+	qop       string
+}
+
+// Validate checks if the digestAuth struct satisfies the defined constraints.
+func (d digestAuth) Validate() error {
+	if d.algorithm != "MD5" && d.algorithm != "SHA-256" {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDigestAlgorithm, d.algorithm)
+	}
+
+	if d.qop != "auth" && d.qop != "auth-int" && d.qop != "none" {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDigestQop, d.qop)
+	}
+
+	return nil
+}
+
+// challenge returns a new Digest challenge, with a fresh nonce.
+func (d digestAuth) challenge() string {
+	challenge := fmt.Sprintf(`Digest realm=%q`, AuthRealm)
+	if d.qop != "none" {
+		challenge += fmt.Sprintf(`, qop=%q`, d.qop)
+	}
+
+	return challenge + fmt.Sprintf(`, algorithm=%s, nonce=%q, opaque=%q`, d.algorithm, randomHex(), randomHex())
+}
+
+// authenticate verifies the digest held by the request's Authorization header.
+func (d digestAuth) authenticate(req *http.Request) (string, string, bool) {
+	scheme, rawParams, _ := strings.Cut(req.Header.Get(echo.HeaderAuthorization), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return "", d.challenge(), false
+	}
+
+	params := parseAuthParams(rawParams)
+
+	algorithm := params["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+
+	if params["username"] != d.user ||
+		params["realm"] != AuthRealm ||
+		params["uri"] != req.RequestURI ||
+		!strings.EqualFold(algorithm, d.algorithm) {
+		return "", d.challenge(), false
+	}
+
+	qop := params["qop"]
+	if (d.qop == "none" && qop != "") || (d.qop != "none" && qop != d.qop) {
+		return "", d.challenge(), false
+	}
+
+	ha1 := d.hash(d.user, AuthRealm, d.password)
+
+	ha2 := d.hash(req.Method, params["uri"])
+	if qop == "auth-int" {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", d.challenge(), false
+		}
+
+		ha2 = d.hash(req.Method, params["uri"], d.hash(string(body)))
+	}
+
+	expected := d.hash(ha1, params["nonce"], ha2)
+	if qop != "" {
+		expected = d.hash(ha1, params["nonce"], params["nc"], params["cnonce"], qop, ha2)
+	}
+
+	if !secureEqual(params["response"], expected) {
+		return "", d.challenge(), false
+	}
+
+	return d.user, "", true
+}
+
+// hash returns the hex encoded hash of the colon separated values, using the
+// digest's algorithm.
+func (d digestAuth) hash(values ...string) string {
+	var h hash.Hash
+	if d.algorithm == "SHA-256" {
+		h = sha256.New()
+	} else {
+		h = md5.New() //nolint:gosec
+	}
+
+	h.Write([]byte(strings.Join(values, ":")))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseAuthParams parses the comma separated key=value parameters of an
+// Authorization header. Values can either be tokens or quoted strings.
+func parseAuthParams(raw string) map[string]string {
+	params := make(map[string]string)
+
+	for raw != "" {
+		raw = strings.TrimLeft(raw, " ,")
+
+		key, rest, found := strings.Cut(raw, "=")
+		if !found {
+			break
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " ")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted string, possibly holding escaped characters
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			raw = rest[min(i+1, len(rest)):]
+		} else {
+			token, remainder, _ := strings.Cut(rest, ",")
+			value.WriteString(strings.TrimSpace(token))
+			raw = remainder
+		}
+
+		params[key] = value.String()
+	}
+
+	return params
+}
+
+// secureEqual compares two strings in constant time.
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// randomHex returns a random 16 bytes hex encoded string.
+func randomHex() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandlers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		target        string
+		header        http.Header
+		wantStatus    int
+		wantChallenge string
+	}{
+		{
+			name:       "basic with valid credentials should succeed",
+			target:     "/auth/basic/user/pass",
+			header:     http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "basic with invalid credentials should fail",
+			target:        "/auth/basic/user/other",
+			header:        http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Basic realm="lhotse"`,
+		},
+		{
+			name:       "bearer with any token should succeed",
+			target:     "/auth/bearer",
+			header:     http.Header{"Authorization": {"Bearer abc"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "bearer with an unexpected token should fail",
+			target:        "/auth/bearer?token=def",
+			header:        http.Header{"Authorization": {"Bearer abc"}},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="lhotse", error="invalid_token"`,
+		},
+		{
+			name:          "bearer without a token should fail",
+			target:        "/auth/bearer",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="lhotse"`,
+		},
+		{
+			name:       "api key in the default header should succeed",
+			target:     "/auth/apikey/secret",
+			header:     http.Header{"X-Api-Key": {"secret"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "api key in a custom query parameter should succeed",
+			target:     "/auth/apikey/secret?query=key&key=secret",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "invalid api key should fail",
+			target:        "/auth/apikey/secret?api_key=other",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `APIKey realm="lhotse", header="X-API-Key", query="api_key"`,
+		},
+		{
+			name:       "invalid latency should fail",
+			target:     "/auth/bearer?latency=invalid",
+			header:     http.Header{"Authorization": {"Bearer abc"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported digest algorithm should fail",
+			target:     "/auth/digest/user/pass?algorithm=SHA-1",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			RegisterHandlers(e, &ServerImpl{})
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantChallenge, rec.Header().Get(echo.HeaderWWWAuthenticate))
+		})
+	}
+}
+
+func TestGetAuthDigestUserPassword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		algorithm  string
+		qop        string
+		password   string
+		wantStatus int
+	}{
+		{"MD5 with auth should succeed", "MD5", "auth", "pass", http.StatusOK},
+		{"SHA-256 with auth should succeed", "SHA-256", "auth", "pass", http.StatusOK},
+		{"MD5 with auth-int should succeed", "MD5", "auth-int", "pass", http.StatusOK},
+		{"MD5 without qop should succeed", "MD5", "none", "pass", http.StatusOK},
+		{"wrong password should fail", "MD5", "auth", "wrong", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			RegisterHandlers(e, &ServerImpl{})
+			target := fmt.Sprintf("/auth/digest/user/pass?algorithm=%s&qop=%s", tt.algorithm, tt.qop)
+
+			// Obtain a challenge
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+			challenge := rec.Header().Get(echo.HeaderWWWAuthenticate)
+			require.True(t, strings.HasPrefix(challenge, "Digest "))
+			params := parseAuthParams(strings.TrimPrefix(challenge, "Digest "))
+			assert.Equal(t, AuthRealm, params["realm"])
+			assert.Equal(t, tt.algorithm, params["algorithm"])
+
+			// Answer the challenge
+			digest := digestAuth{user: "user", password: tt.password, algorithm: tt.algorithm}
+			ha1 := digest.hash("user", AuthRealm, tt.password)
+			ha2 := digest.hash(http.MethodGet, target)
+			if tt.qop == "auth-int" {
+				ha2 = digest.hash(http.MethodGet, target, digest.hash(""))
+			}
+
+			authorization := fmt.Sprintf(
+				`Digest username="user", realm=%q, nonce=%q, uri=%q, algorithm=%s, opaque=%q`,
+				AuthRealm, params["nonce"], target, tt.algorithm, params["opaque"],
+			)
+			if tt.qop == "none" {
+				authorization += fmt.Sprintf(`, response=%q`, digest.hash(ha1, params["nonce"], ha2))
+			} else {
+				response := digest.hash(ha1, params["nonce"], "00000001", "0a4f113b", tt.qop, ha2)
+				authorization += fmt.Sprintf(`, qop=%s, nc=00000001, cnonce="0a4f113b", response=%q`, tt.qop, response)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+			rec = httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestParseAuthParams(t *testing.T) {
+	t.Parallel()
+
+	got := parseAuthParams(`username="Mufasa", realm="http-auth@example.org", qop=auth, nc=00000001, uri="/dir/\"index\".html"`)
+
+	assert.Equal(t, map[string]string{
+		"username": "Mufasa",
+		"realm":    "http-auth@example.org",
+		"qop":      "auth",
+		"nc":       "00000001",
+		"uri":      `/dir/"index".html`,
+	}, got)
+}
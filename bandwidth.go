@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrBandwidthBounds is returned when a bandwidth is expressed as a size range.
+var ErrBandwidthBounds = errors.New("bandwidth cannot be a range")
+
+// Bandwidth represents a transfer rate, in bytes per second.
+//
+// A zero bandwidth means unlimited.
+type Bandwidth ByteUnit
+
+// ParseBandwidth parses a bandwidth string and returns a Bandwidth.
+//
+// The bandwidth string uses the same format as a single size, such as "64kb",
+// and is understood as a number of bytes per second.
+func ParseBandwidth(bandwidth string) (Bandwidth, error) {
+	size, err := ParseSize(bandwidth)
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing bandwidth: %w", err)
+	}
+
+	if size.UpperBound != 0 {
+		return 0, ErrBandwidthBounds
+	}
+
+	if err = size.Validate(); err != nil {
+		return 0, err
+	}
+
+	return Bandwidth(size.LowerBound), nil
+}
+
+// String returns the string representation of the bandwidth.
+func (b Bandwidth) String() string {
+	return fmt.Sprintf("%db", b)
+}
+
+// MarshalText implements encoding.TextMarshaler, using the same format as ParseBandwidth.
+func (b Bandwidth) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using the same format
+// as ParseBandwidth. An empty text produces an unlimited bandwidth.
+func (b *Bandwidth) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*b = 0
+		return nil
+	}
+
+	bandwidth, err := ParseBandwidth(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = bandwidth
+
+	return nil
+}
+
+// throttledWriter is a http.ResponseWriter writing the response body no
+// faster than its bandwidth allows.
+//
+// The body is written in chunks of a tenth of the bandwidth, each of them
+// flushed to the client, so that the transfer rate is observable.
+type throttledWriter struct {
+	http.ResponseWriter
+
+	bandwidth Bandwidth
+	start     time.Time
+	written   int64
+}
+
+// newThrottledWriter wraps the writer so that it writes no faster than the bandwidth.
+func newThrottledWriter(w http.ResponseWriter, bandwidth Bandwidth) *throttledWriter {
+	return &throttledWriter{
+		ResponseWriter: w,
+		bandwidth:      bandwidth,
+	}
+}
+
+// Write writes the data to the underlying writer, pacing it to the bandwidth.
+func (w *throttledWriter) Write(data []byte) (int, error) {
+	if w.bandwidth <= 0 {
+		return w.ResponseWriter.Write(data)
+	}
+
+	if w.start.IsZero() {
+		w.start = time.Now()
+	}
+
+	chunkSize := max(int(w.bandwidth)/10, 1)
+
+	total := 0
+	for len(data) > 0 {
+		chunk := data[:min(chunkSize, len(data))]
+
+		n, err := w.ResponseWriter.Write(chunk)
+		total += n
+		w.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+
+		w.Flush()
+
+		// Wait until the bandwidth allows for the bytes written so far
+		expected := time.Duration(float64(w.written) / float64(w.bandwidth) * float64(time.Second))
+		time.Sleep(time.Until(w.start.Add(expected)))
+
+		data = data[len(chunk):]
+	}
+
+	return total, nil
+}
+
+// Flush flushes the underlying writer, if it supports it.
+func (w *throttledWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for use by http.ResponseController.
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		bandwidth string
+		want      Bandwidth
+		wantErr   bool
+	}{
+		{
+			name:      "bytes per second should be parsed",
+			bandwidth: "512b",
+			want:      512,
+		},
+		{
+			name:      "kilobytes per second should be parsed",
+			bandwidth: "64kb",
+			want:      64 * 1024,
+		},
+		{
+			name:      "ranges should fail",
+			bandwidth: "1kb-2kb",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid bandwidth should fail",
+			bandwidth: "fast",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseBandwidth(tt.bandwidth)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestThrottledWriter(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	w := newThrottledWriter(rec, 1000)
+
+	start := time.Now()
+	n, err := w.Write(make([]byte, 200))
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, n)
+	assert.Equal(t, 200, rec.Body.Len())
+	assert.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
+	assert.True(t, rec.Flushed)
+}
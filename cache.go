@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultCacheMaxAge is the default number of seconds the /cache responses are fresh for.
+const DefaultCacheMaxAge = 60
+
+// ErrInvalidChangeInterval is returned when the interval at which a cacheable payload changes is not positive.
+var ErrInvalidChangeInterval = errors.New("change interval must be positive")
+
+// GetCacheSize is a handler returning a cacheable payload of the requested size.
+//
+// The payload is generated deterministically from its size and version, so
+// that it is identical across requests until its version changes. Unless
+// a change interval is requested, the payload's version is the time the
+// server started.
+//
+// The response carries ETag, Last-Modified, Cache-Control and Vary headers,
+// and conditional requests whose validators match the payload are answered
+// with a 304.
+func (s *ServerImpl) GetCacheSize(ctx echo.Context, size string, params GetCacheSizeParams) error {
+	sizeBounds, err := ParseSize(size)
+	if err == nil {
+		err = sizeBounds.Validate()
+	}
+	if err != nil {
+		slog.Error(
+			"failed parsing size",
+			"handler", "GetCacheSize",
+			"size", size,
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	modified := s.started
+	if params.ChangeEvery != nil {
+		interval, parseErr := time.ParseDuration(*params.ChangeEvery)
+		if parseErr == nil && interval <= 0 {
+			parseErr = ErrInvalidChangeInterval
+		}
+		if parseErr != nil {
+			slog.Error(
+				"failed parsing change interval",
+				"handler", "GetCacheSize",
+				"change_every", *params.ChangeEvery,
+				"error_message", parseErr.Error(),
+			)
+
+			return ctx.String(http.StatusBadRequest, parseErr.Error())
+		}
+
+		modified = time.Now().Truncate(interval)
+	}
+
+	// HTTP dates have a one second precision
+	modified = modified.Truncate(time.Second)
+	seed := cacheSeed(sizeBounds, modified)
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	header.Set("ETag", cacheETag(sizeBounds, seed, params.Weak != nil && *params.Weak))
+	header.Set("Cache-Control", cacheControl(params))
+	if params.Vary != nil {
+		header.Set(echo.HeaderVary, *params.Vary)
+	}
+
+	// ServeContent sets Last-Modified, and handles conditional requests
+	http.ServeContent(ctx.Response(), ctx.Request(), "", modified, bytes.NewReader(sizeBounds.SeededPayload(seed)))
+
+	return nil
+}
+
+// cacheSeed returns the seed the payload of the size is generated from, at
+// the version identified by its modification time.
+func cacheSeed(size Size, modified time.Time) int64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "%s@%d", size, modified.Unix())
+
+	return int64(hash.Sum64())
+}
+
+// cacheETag returns the entity tag of the payload of the size generated from
+// the seed. Payloads of different sizes, generated from the same seed, differ.
+func cacheETag(size Size, seed int64, weak bool) string {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "%s#%d", size, seed)
+
+	etag := fmt.Sprintf("%q", fmt.Sprintf("%016x", hash.Sum64()))
+	if weak {
+		return "W/" + etag
+	}
+
+	return etag
+}
+
+// cacheControl returns the Cache-Control header requested by the parameters.
+func cacheControl(params GetCacheSizeParams) string {
+	if params.CacheControl != nil {
+		return *params.CacheControl
+	}
+
+	maxAge := DefaultCacheMaxAge
+	if params.MaxAge != nil {
+		maxAge = *params.MaxAge
+	}
+
+	return fmt.Sprintf("public, max-age=%d", maxAge)
+}
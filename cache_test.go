@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCacheSize(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, NewServerImpl(Config{}))
+
+	serve := func(target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	first := serve("/cache/1kb-2kb?vary=Accept-Encoding", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, echo.MIMEOctetStream, first.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "public, max-age=60", first.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Encoding", first.Header().Get(echo.HeaderVary))
+	assert.NotEmpty(t, first.Header().Get(echo.HeaderLastModified))
+	etag := first.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+
+	second := serve("/cache/1kb-2kb", nil)
+	assert.Equal(t, first.Body.Bytes(), second.Body.Bytes(), "payload should be deterministic")
+	assert.Equal(t, etag, second.Header().Get("ETag"))
+
+	tests := []struct {
+		name       string
+		target     string
+		header     http.Header
+		wantStatus int
+		wantHeader http.Header
+	}{
+		{
+			name:       "matching If-None-Match should respond with a 304",
+			target:     "/cache/1kb-2kb",
+			header:     http.Header{"If-None-Match": {`"other", ` + etag}},
+			wantStatus: http.StatusNotModified,
+			wantHeader: http.Header{"Etag": {etag}, "Cache-Control": {"public, max-age=60"}},
+		},
+		{
+			name:       "weak If-None-Match should match a strong ETag",
+			target:     "/cache/1kb-2kb",
+			header:     http.Header{"If-None-Match": {"W/" + etag}},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "mismatching If-None-Match should respond with the payload",
+			target:     "/cache/1kb-2kb",
+			header:     http.Header{"If-None-Match": {`"other"`}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "If-Modified-Since the last modification should respond with a 304",
+			target:     "/cache/1kb-2kb",
+			header:     http.Header{"If-Modified-Since": {first.Header().Get(echo.HeaderLastModified)}},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "If-Modified-Since before the last modification should respond with the payload",
+			target:     "/cache/1kb-2kb",
+			header:     http.Header{"If-Modified-Since": {time.Unix(0, 0).UTC().Format(http.TimeFormat)}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "If-None-Match should take precedence over If-Modified-Since",
+			target:     "/cache/1kb-2kb",
+			header:     http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {first.Header().Get(echo.HeaderLastModified)}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "cache headers should be configurable",
+			target:     "/cache/1kb?max_age=10&weak=true",
+			wantStatus: http.StatusOK,
+			wantHeader: http.Header{"Cache-Control": {"public, max-age=10"}},
+		},
+		{
+			name:       "cache control should replace max age",
+			target:     "/cache/1kb?max_age=10&cache_control=no-store",
+			wantStatus: http.StatusOK,
+			wantHeader: http.Header{"Cache-Control": {"no-store"}},
+		},
+		{
+			name:       "invalid size should fail",
+			target:     "/cache/1zb",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid change interval should fail",
+			target:     "/cache/1kb?change_every=-1s",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := serve(tt.target, tt.header)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			for name := range tt.wantHeader {
+				assert.Equal(t, tt.wantHeader.Get(name), rec.Header().Get(name), name)
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				assert.Empty(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestGetCacheSize_ChangeEvery(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, NewServerImpl(Config{}))
+
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cache/64b?change_every=1s", nil))
+		return rec
+	}
+
+	first := serve()
+	require.Equal(t, http.StatusOK, first.Code)
+
+	// Wait for the next version of the payload
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+	second := serve()
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.NotEqual(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+	assert.NotEqual(t, first.Header().Get(echo.HeaderLastModified), second.Header().Get(echo.HeaderLastModified))
+}
+
+func TestCacheETag(t *testing.T) {
+	t.Parallel()
+
+	etag := cacheETag(Size{LowerBound: 100}, 42, false)
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+	assert.Equal(t, etag, cacheETag(Size{LowerBound: 100}, 42, false))
+	assert.Equal(t, "W/"+etag, cacheETag(Size{LowerBound: 100}, 42, true))
+	assert.NotEqual(t, etag, cacheETag(Size{LowerBound: 100}, 43, false), "different seeds should have different tags")
+	assert.NotEqual(t, etag, cacheETag(Size{LowerBound: 200}, 42, false), "different sizes should have different tags")
+}
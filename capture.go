@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrCaptureDisabled is returned when the request capture is disabled.
+var ErrCaptureDisabled = errors.New("request capture is disabled")
+
+// DefaultCaptureSize is the number of requests captured when none is specified.
+//
+// The capture is opt-in, as it copies the headers and body preview of every
+// request, under a lock shared by every request.
+const DefaultCaptureSize = 0
+
+// DefaultCaptureBodyPreview is the number of request body bytes captured when none is specified.
+const DefaultCaptureBodyPreview = 1024
+
+// CaptureOptions holds the options of the request capture.
+type CaptureOptions struct {
+	// Size is the number of most recent requests kept. Zero disables the capture.
+	Size int
+
+	// BodyPreview is the number of bytes of the request body kept, and of
+	// the response body when ResponseBody is set.
+	BodyPreview int
+
+	// ResponseBody enables capturing the response body, in addition to the
+	// request body.
+	ResponseBody bool
+}
+
+// Validate checks if the CaptureOptions struct satisfies the defined constraints.
+func (o CaptureOptions) Validate() error {
+	if o.Size < 0 {
+		return errors.New("size cannot be negative")
+	}
+
+	if o.BodyPreview < 0 {
+		return errors.New("body preview cannot be negative")
+	}
+
+	return nil
+}
+
+// CapturedRequest holds what the server received, and how it responded.
+type CapturedRequest struct {
+	// ID identifies the request. IDs are assigned in capture order, starting at 1.
+	ID int64 `json:"id"`
+
+	// Time is when the request was received.
+	Time time.Time `json:"time"`
+
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	Query      string      `json:"query"`
+	Proto      string      `json:"proto"`
+	Host       string      `json:"host"`
+	RemoteAddr string      `json:"remote_addr"`
+	Headers    http.Header `json:"headers"`
+
+	// TLS is true when the request was received over TLS.
+	TLS bool `json:"tls"`
+
+	// Body holds the first bytes of the request body, up to the body preview size.
+	Body string `json:"body"`
+
+	// BodySize is the size of the request body, or -1 if unknown.
+	BodySize int64 `json:"body_size"`
+
+	// BodyTruncated is true when Body does not hold the whole request body.
+	BodyTruncated bool `json:"body_truncated"`
+
+	// Status is the status of the response, or 0 if the connection was
+	// dropped without responding.
+	Status int `json:"status"`
+
+	// ResponseHeaders are the headers of the response.
+	ResponseHeaders http.Header `json:"response_headers"`
+
+	// ResponseBody holds the first bytes of the response body, up to the
+	// body preview size, when response bodies are captured.
+	ResponseBody string `json:"response_body,omitempty"`
+
+	// ResponseBodyTruncated is true when ResponseBody does not hold the whole
+	// response body.
+	ResponseBodyTruncated bool `json:"response_body_truncated,omitempty"`
+
+	// ResponseSize is the size of the response body.
+	ResponseSize int64 `json:"response_size"`
+
+	// WaitMs is how long the server took to start responding, in milliseconds.
+	WaitMs float64 `json:"wait_ms"`
+
+	// DurationMs is how long the request took to be handled, in milliseconds.
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// CaptureFilter selects captured requests.
+//
+// Empty fields match any request.
+type CaptureFilter struct {
+	// Method matches the request method, case insensitively.
+	Method string
+
+	// Path matches the request path, either exactly or as a path.Match pattern,
+	// such as "/data/*".
+	Path string
+
+	// Headers match the request headers. Each of them is either a header name,
+	// matching requests holding the header, or a "name:value" pair, matching
+	// requests holding the header with that value.
+	Headers []string
+}
+
+// Match returns true if the captured request satisfies the filter.
+func (f CaptureFilter) Match(req CapturedRequest) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, req.Method) {
+		return false
+	}
+
+	if f.Path != "" && f.Path != req.Path {
+		if matched, err := path.Match(f.Path, req.Path); err != nil || !matched {
+			return false
+		}
+	}
+
+	for _, header := range f.Headers {
+		name, value, hasValue := strings.Cut(header, ":")
+
+		values, ok := req.Headers[http.CanonicalHeaderKey(strings.TrimSpace(name))]
+		if !ok {
+			return false
+		}
+
+		if hasValue && !slices.Contains(values, strings.TrimSpace(value)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RequestCapture holds the most recent requests received by the server, in
+// a bounded ring buffer.
+//
+// It is safe for concurrent use.
+type RequestCapture struct {
+	mu       sync.RWMutex
+	options  CaptureOptions
+	requests []CapturedRequest
+	next     int
+	lastID   int64
+	evicted  int64
+}
+
+// NewRequestCapture creates a new, empty, RequestCapture instance.
+func NewRequestCapture(options CaptureOptions) *RequestCapture {
+	return &RequestCapture{
+		options:  options,
+		requests: make([]CapturedRequest, 0, options.Size),
+	}
+}
+
+// Enabled returns true if the capture keeps requests.
+func (c *RequestCapture) Enabled() bool {
+	return c.options.Size > 0
+}
+
+// Add captures the request, evicting the oldest one if the buffer is full,
+// and returns it with its assigned ID.
+func (c *RequestCapture) Add(req CapturedRequest) CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastID++
+	req.ID = c.lastID
+
+	if !c.Enabled() {
+		return req
+	}
+
+	if len(c.requests) < c.options.Size {
+		c.requests = append(c.requests, req)
+	} else {
+		c.requests[c.next] = req
+		c.evicted++
+	}
+	c.next = (c.next + 1) % c.options.Size
+
+	return req
+}
+
+// Evicted returns the number of requests evicted to make room for more
+// recent ones since the capture was last cleared.
+func (c *RequestCapture) Evicted() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.evicted
+}
+
+// List returns the captured requests satisfying the filter, from the oldest
+// to the most recent.
+func (c *RequestCapture) List(filter CaptureFilter) []CapturedRequest {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	requests := make([]CapturedRequest, 0, len(c.requests))
+	for i := range c.requests {
+		// Once the buffer is full, the oldest request is the next to be
+		// overwritten. Until then, next is the length of the buffer.
+		req := c.requests[(c.next+i)%len(c.requests)]
+		if filter.Match(req) {
+			requests = append(requests, req)
+		}
+	}
+
+	return requests
+}
+
+// Get returns the captured request with the provided ID, and false if it
+// was never captured or has been evicted.
+func (c *RequestCapture) Get(id int64) (CapturedRequest, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, req := range c.requests {
+		if req.ID == id {
+			return req, true
+		}
+	}
+
+	return CapturedRequest{}, false
+}
+
+// Clear removes every captured request. IDs keep increasing across clears.
+func (c *RequestCapture) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = c.requests[:0]
+	c.next = 0
+	c.evicted = 0
+}
+
+// CaptureMiddleware returns a middleware capturing every request whose path
+// does not start with one of the skipped prefixes.
+func CaptureMiddleware(capture *RequestCapture, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if !capture.Enabled() || hasAnyPathPrefix(req.URL.Path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			captured := CapturedRequest{
+				Time:       time.Now(),
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Query:      req.URL.RawQuery,
+				Proto:      req.Proto,
+				Host:       req.Host,
+				RemoteAddr: req.RemoteAddr,
+				Headers:    req.Header.Clone(),
+				TLS:        req.TLS != nil,
+				BodySize:   req.ContentLength,
+			}
+
+			// Read the preview of the body, and put it back in front of the
+			// rest of the body for the handler to read.
+			if req.Body != nil && req.Body != http.NoBody {
+				preview, _ := io.ReadAll(io.LimitReader(req.Body, int64(capture.options.BodyPreview)+1))
+				req.Body = readCloser{io.MultiReader(bytes.NewReader(preview), req.Body), req.Body}
+
+				captured.BodyTruncated = len(preview) > capture.options.BodyPreview
+				captured.Body = string(preview[:min(len(preview), capture.options.BodyPreview)])
+				if captured.BodySize < 0 && !captured.BodyTruncated {
+					captured.BodySize = int64(len(preview))
+				}
+			}
+
+			// Record when the response starts, and a preview of its body
+			res := ctx.Response()
+			var respondedAt time.Time
+			res.Before(func() { respondedAt = time.Now() })
+
+			hijack := &hijackRecorder{ResponseWriter: res.Writer}
+			res.Writer = hijack
+
+			var responseBody *previewWriter
+			if capture.options.ResponseBody {
+				responseBody = &previewWriter{ResponseWriter: res.Writer, limit: capture.options.BodyPreview}
+				res.Writer = responseBody
+			}
+
+			// Handle the error right away, so that the captured status is the one sent
+			err := next(ctx)
+			if err != nil {
+				ctx.Error(err)
+			}
+
+			captured.Status = res.Status
+			if hijack.hijacked {
+				captured.Status = 0
+			}
+			captured.ResponseHeaders = res.Header().Clone()
+			captured.ResponseSize = res.Size
+			captured.DurationMs = milliseconds(time.Since(captured.Time))
+			if !respondedAt.IsZero() {
+				captured.WaitMs = milliseconds(respondedAt.Sub(captured.Time))
+			}
+			if responseBody != nil {
+				captured.ResponseBody = responseBody.preview.String()
+				captured.ResponseBodyTruncated = int64(responseBody.preview.Len()) < res.Size
+			}
+
+			capture.Add(captured)
+
+			return nil
+		}
+	}
+}
+
+// previewWriter is a http.ResponseWriter keeping the first bytes written to it.
+type previewWriter struct {
+	http.ResponseWriter
+
+	preview bytes.Buffer
+	limit   int
+}
+
+// Write writes the data to the underlying writer, keeping what fits in the preview.
+func (w *previewWriter) Write(data []byte) (int, error) {
+	if remaining := w.limit - w.preview.Len(); remaining > 0 {
+		w.preview.Write(data[:min(remaining, len(data))])
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush flushes the underlying writer, if it supports it.
+func (w *previewWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for use by http.ResponseController.
+func (w *previewWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// hijackRecorder is a http.ResponseWriter recording whether its connection
+// was hijacked, such as to drop it without responding.
+type hijackRecorder struct {
+	http.ResponseWriter
+
+	hijacked bool
+}
+
+// Hijack hijacks the underlying writer's connection, if it supports it.
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Flush flushes the underlying writer, if it supports it.
+func (w *hijackRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for use by http.ResponseController.
+func (w *hijackRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// milliseconds returns the duration as a fractional number of milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// readCloser combines a reader with the closer of another.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// hasAnyPathPrefix returns true if the URL path is, or is under, any of the
+// prefixes, matching them on path segment boundaries: "/admin" matches
+// "/admin" and "/admin/settings", but not "/administration".
+func hasAnyPathPrefix(urlPath string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestCapture(t *testing.T) {
+	t.Parallel()
+
+	capture := NewRequestCapture(CaptureOptions{Size: 2})
+	for _, path := range []string{"/a", "/b", "/c"} {
+		capture.Add(CapturedRequest{Path: path})
+	}
+
+	requests := capture.List(CaptureFilter{})
+	require.Len(t, requests, 2)
+	assert.Equal(t, int64(2), requests[0].ID)
+	assert.Equal(t, "/b", requests[0].Path)
+	assert.Equal(t, int64(3), requests[1].ID)
+	assert.Equal(t, "/c", requests[1].Path)
+
+	_, ok := capture.Get(1)
+	assert.False(t, ok, "evicted request should not be found")
+	assert.Equal(t, int64(1), capture.Evicted())
+
+	got, ok := capture.Get(3)
+	assert.True(t, ok)
+	assert.Equal(t, "/c", got.Path)
+
+	capture.Clear()
+	assert.Empty(t, capture.List(CaptureFilter{}))
+	assert.Zero(t, capture.Evicted(), "clearing should reset evictions")
+
+	assert.Equal(t, int64(4), capture.Add(CapturedRequest{Path: "/d"}).ID, "IDs should keep increasing across clears")
+}
+
+func TestCaptureFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	req := CapturedRequest{
+		Method:  http.MethodPost,
+		Path:    "/data/1kb",
+		Headers: http.Header{"X-Tenant": []string{"a"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter CaptureFilter
+		want   bool
+	}{
+		{
+			name:   "empty filter should match",
+			filter: CaptureFilter{},
+			want:   true,
+		},
+		{
+			name:   "method should match case insensitively",
+			filter: CaptureFilter{Method: "post"},
+			want:   true,
+		},
+		{
+			name:   "different method should not match",
+			filter: CaptureFilter{Method: http.MethodGet},
+			want:   false,
+		},
+		{
+			name:   "path pattern should match",
+			filter: CaptureFilter{Path: "/data/*"},
+			want:   true,
+		},
+		{
+			name:   "different path should not match",
+			filter: CaptureFilter{Path: "/latency/*"},
+			want:   false,
+		},
+		{
+			name:   "header name should match",
+			filter: CaptureFilter{Headers: []string{"x-tenant"}},
+			want:   true,
+		},
+		{
+			name:   "header name and value should match",
+			filter: CaptureFilter{Headers: []string{"X-Tenant: a"}},
+			want:   true,
+		},
+		{
+			name:   "different header value should not match",
+			filter: CaptureFilter{Headers: []string{"X-Tenant:b"}},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.filter.Match(req))
+		})
+	}
+}
+
+func TestHasAnyPathPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"prefix itself should match", "/admin", true},
+		{"path under the prefix should match", "/admin/settings", true},
+		{"path sharing the prefix's characters should not match", "/administration", false},
+		{"dashed path sharing the prefix's characters should not match", "/admin-ui", false},
+		{"unrelated path should not match", "/data/1kb", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, hasAnyPathPrefix(tt.path, []string{"/metrics", DefaultAdminPrefix}))
+		})
+	}
+}
+
+func TestCaptureMiddleware(t *testing.T) {
+	t.Parallel()
+
+	capture := NewRequestCapture(CaptureOptions{Size: 10, BodyPreview: 4, ResponseBody: true})
+
+	e := echo.New()
+	e.Use(CaptureMiddleware(capture, "/admin"))
+	e.POST("/echo", func(ctx echo.Context) error {
+		body, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		return ctx.String(http.StatusAccepted, string(body))
+	})
+	e.GET("/admin/requests", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	for _, target := range []string{"/echo", "/admin/requests", "/missing"} {
+		method := http.MethodPost
+		if target == "/admin/requests" {
+			method = http.MethodGet
+		}
+
+		req := httptest.NewRequest(method, target+"?q=1", strings.NewReader("hello world"))
+		req.Header.Set("X-Tenant", "a")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if target == "/echo" {
+			assert.Equal(t, "hello world", rec.Body.String(), "handler should read the whole body")
+		}
+	}
+
+	requests := capture.List(CaptureFilter{})
+	require.Len(t, requests, 2, "skipped prefixes should not be captured")
+
+	assert.Equal(t, "/echo", requests[0].Path)
+	assert.Equal(t, "q=1", requests[0].Query)
+	assert.Equal(t, "a", requests[0].Headers.Get("X-Tenant"))
+	assert.Equal(t, "hell", requests[0].Body)
+	assert.True(t, requests[0].BodyTruncated)
+	assert.Equal(t, int64(11), requests[0].BodySize)
+	assert.Equal(t, http.StatusAccepted, requests[0].Status)
+	assert.Equal(t, int64(11), requests[0].ResponseSize)
+	assert.Equal(t, "hell", requests[0].ResponseBody)
+	assert.True(t, requests[0].ResponseBodyTruncated)
+	assert.False(t, requests[0].TLS)
+	assert.Equal(t, echo.MIMETextPlainCharsetUTF8, requests[0].ResponseHeaders.Get(echo.HeaderContentType))
+
+	assert.Equal(t, "/missing", requests[1].Path)
+	assert.Equal(t, http.StatusNotFound, requests[1].Status, "errors should be captured with the status sent")
+}
+
+func TestCaptureMiddleware_Dropped(t *testing.T) {
+	t.Parallel()
+
+	capture := NewRequestCapture(CaptureOptions{Size: 10, BodyPreview: 4, ResponseBody: true})
+
+	e := echo.New()
+	e.Use(CaptureMiddleware(capture))
+	e.GET("/drop", func(ctx echo.Context) error {
+		conn, _, err := http.NewResponseController(ctx.Response().Writer).Hijack()
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	})
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/drop") //nolint:noctx
+	if err == nil {
+		res.Body.Close()
+	}
+	require.Error(t, err, "dropped request should not be responded to")
+
+	// The request is captured once the handler returns, which the client does not wait for
+	require.Eventually(t, func() bool { return len(capture.List(CaptureFilter{})) == 1 }, time.Second, time.Millisecond)
+	assert.Zero(t, capture.List(CaptureFilter{})[0].Status, "dropped request should be captured without status")
+}
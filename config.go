@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// DefaultAddr is the address the server listens on when none is provided.
+const DefaultAddr = ":3434"
+
+// Config holds the server's configuration.
+type Config struct {
+	// Addr is the address the server listens on, unless Listeners are set.
+	Addr string
+
+	// Listeners holds the addresses the server listens on, each with its own
+	// TLS setting and behaviour profile. It replaces Addr when set.
+	Listeners Listeners
+
+	// Connection holds the connection lifecycle options.
+	Connection ConnectionOptions
+
+	// RateLimit holds the default rate limit options.
+	RateLimit RateLimitOptions
+
+	// Store holds the options of the resource store.
+	Store StoreOptions
+
+	// Admin holds the options of the admin API.
+	Admin AdminOptions
+
+	// Capture holds the options of the request capture.
+	Capture CaptureOptions
+
+	// RoutesFile is the path of the file defining additional routes, if any.
+	RoutesFile string
+
+	// HARFile is the path of the file the captured requests are written to,
+	// as an HTTP Archive, on shutdown.
+	HARFile string
+
+	// Replay holds the options of the replay mode.
+	Replay ReplayOptions
+
+	// Mock holds the options of the OpenAPI mock.
+	Mock MockOptions
+
+	// Proxy holds the options of the reverse proxy mode.
+	Proxy ProxyOptions
+
+	// TCPProxy holds the options of the TCP proxy mode.
+	TCPProxy TCPProxyOptions
+
+	// ForwardProxy holds the options of the forward proxy mode.
+	ForwardProxy ForwardProxyOptions
+
+	// Workers holds the options of the worker pool.
+	Workers WorkerPoolOptions
+
+	// Queueing holds the options of the queueing model.
+	Queueing QueueingOptions
+
+	// ScheduleFile is the path of the file defining the schedule applied
+	// from the server's start, if any.
+	ScheduleFile string
+}
+
+// ParseConfig parses the command line arguments and returns a Config struct.
+//
+// It returns an error if the arguments cannot be parsed, or if the resulting
+// configuration is invalid.
+func ParseConfig(args []string) (config Config, err error) {
+	flags := flag.NewFlagSet("lhotse", flag.ContinueOnError)
+
+	flags.StringVar(&config.Addr, "addr", DefaultAddr, "address to listen on")
+	flags.Var(&config.Listeners, "listen", "listener URL, such as tcp://:8080, tcp6://[::1]:8080 or unix:///tmp/lhotse.sock, with optional cert, key, proxy_protocol, latency, error_rate, error_status and bandwidth query parameters (repeatable, replaces -addr)")
+
+	// Connection lifecycle options
+	flags.BoolVar(&config.Connection.Close, "conn-close", false, "close every connection after responding")
+	flags.Int64Var(&config.Connection.MaxRequests, "conn-max-requests", 0, "maximum number of requests served per connection (0 means unlimited)")
+	flags.DurationVar(&config.Connection.MaxAge, "conn-max-age", 0, "maximum age of a connection (0 means unlimited)")
+	flags.DurationVar(&config.Connection.IdleTimeout, "conn-idle-timeout", 0, "how long idle keep-alive connections are kept open (0 means no timeout)")
+
+	// Rate limit options
+	rateLimit := DefaultRateLimitOptions()
+	flags.IntVar(&config.RateLimit.Limit, "ratelimit-limit", rateLimit.Limit, "default number of requests allowed per rate limit window")
+	flags.DurationVar(&config.RateLimit.Window, "ratelimit-window", rateLimit.Window, "default rate limit window")
+	algorithm := flags.String("ratelimit-algorithm", string(rateLimit.Algorithm), "default rate limit algorithm (token-bucket or sliding-window)")
+	flags.StringVar(&config.RateLimit.Key, "ratelimit-key", rateLimit.Key, "default rate limit key (ip, apikey or header:<name>)")
+
+	// Worker pool options
+	workers := DefaultWorkerPoolOptions()
+	flags.IntVar(&config.Workers.Workers, "workers", 0, "number of requests served at once (0 means unlimited)")
+	flags.IntVar(&config.Workers.QueueDepth, "worker-queue-depth", 0, "number of requests waiting for a worker at most, beyond which they are rejected")
+	flags.DurationVar(&config.Workers.QueueTimeout, "worker-queue-timeout", 0, "how long requests wait for a worker at most, before being rejected (0 means no timeout)")
+	flags.IntVar(&config.Workers.RejectStatus, "worker-reject-status", workers.RejectStatus, "status of the requests rejected by the worker pool")
+	flags.BoolVar(&config.Workers.Drop, "worker-drop", false, "drop the connection of rejected requests rather than responding")
+
+	// Queueing model options
+	flags.IntVar(&config.Queueing.Workers, "queueing-workers", 0, "number of workers of the queueing model deriving latency from load (0 disables it)")
+	flags.Float64Var(&config.Queueing.ServiceRate, "queueing-service-rate", 0, "mean number of requests served per second by each worker of the queueing model")
+
+	flags.StringVar(&config.RoutesFile, "routes", "", "path of a YAML or JSON file defining additional routes")
+	flags.StringVar(&config.ScheduleFile, "schedule", "", "path of a YAML or JSON file defining how latency and errors evolve from the server's start")
+
+	// Replay options
+	replay := DefaultReplayOptions()
+	flags.StringVar(&config.Replay.File, "replay", "", "path of a HAR file whose recorded responses are replayed")
+	flags.BoolVar(&config.Replay.Timings, "replay-timings", false, "replay the recorded duration of each response as latency")
+	replayMatch := flags.String("replay-match", string(replay.Match), "how strictly requests must match a recording (path, query or body)")
+	flags.StringVar(&config.Replay.Fallback, "replay-fallback", replay.Fallback, "how unmatched requests are answered (routes, or a status such as 404)")
+
+	// OpenAPI mock options
+	mock := DefaultMockOptions()
+	flags.StringVar(&config.Mock.File, "mock", "", "path of an OpenAPI 3 document whose operations are mocked")
+	flags.BoolVar(&config.Mock.Validate, "mock-validate", mock.Validate, "validate requests against the mocked OpenAPI document")
+	mockLatency := flags.String("mock-latency", "", "default latency of the mocked operations (e.g. 10ms or 10ms-50ms)")
+	flags.Float64Var(&config.Mock.Behaviour.ErrorRate, "mock-error-rate", mock.Behaviour.ErrorRate, "default probability, between 0 and 1, for mocked operations to respond with an error")
+	flags.IntVar(&config.Mock.Behaviour.ErrorStatus, "mock-error-status", mock.Behaviour.ErrorStatus, "default status of the errors injected into mocked operations")
+
+	// Reverse proxy options
+	flags.StringVar(&config.Proxy.Upstream, "proxy-upstream", "", "URL of the service requests are forwarded to, in reverse proxy mode")
+	flags.StringVar(&config.Proxy.RulesFile, "proxy-rules", "", "path of a YAML or JSON file defining the faults injected into the forwarded requests")
+
+	// TCP proxy options
+	flags.StringVar(&config.TCPProxy.Addr, "tcp-proxy-addr", "", "address the TCP proxy listens on, whose toxics are controlled by the admin API")
+	flags.StringVar(&config.TCPProxy.Upstream, "tcp-proxy-upstream", "", "address of the service the TCP proxy forwards connections to")
+
+	// Forward proxy options
+	flags.StringVar(&config.ForwardProxy.Addr, "forward-proxy-addr", "", "address the forward HTTP proxy, tunnelling CONNECT requests, listens on")
+	flags.StringVar(&config.ForwardProxy.Auth, "forward-proxy-auth", "", "username:password credentials the forward proxy requires through Proxy-Authorization")
+	forwardProxyLatency := flags.String("forward-proxy-latency", "", "latency of each tunnel, and each request forwarded outside of a tunnel (e.g. 10ms or 10ms-50ms)")
+
+	// Resource store options
+	config.Store.Latencies = StoreLatencies{}
+	flags.IntVar(&config.Store.Capacity, "store-capacity", 0, "maximum number of resources held by the store (0 means unlimited)")
+	flags.Var(config.Store.Latencies, "store-latency", "latency of the store operations, as operation=latency pairs (e.g. create=10ms,read=1ms-5ms)")
+
+	// Admin API options
+	flags.StringVar(&config.Admin.Addr, "admin-addr", "", "address the admin API listens on (defaults to serving it on the main address)")
+	flags.StringVar(&config.Admin.Prefix, "admin-prefix", DefaultAdminPrefix, "path prefix the admin API is served under")
+
+	// Request capture options
+	flags.IntVar(&config.Capture.Size, "capture-size", DefaultCaptureSize, "number of most recent requests captured, for the admin API to inspect (0, the default, disables the capture)")
+	flags.IntVar(&config.Capture.BodyPreview, "capture-body-preview", DefaultCaptureBodyPreview, "number of request and response body bytes captured")
+	flags.BoolVar(&config.Capture.ResponseBody, "capture-response-body", false, "capture response bodies, in addition to request bodies")
+	flags.StringVar(&config.HARFile, "har-file", "", "path of the file the captured requests are written to, as an HTTP Archive, on shutdown")
+
+	if err = flags.Parse(args); err != nil {
+		return config, err
+	}
+
+	config.RateLimit.Algorithm = RateLimitAlgorithm(*algorithm)
+	config.Replay.Match = ReplayMatch(*replayMatch)
+
+	if *mockLatency != "" {
+		if config.Mock.Behaviour.Latency, err = ParseValidLatency(*mockLatency); err != nil {
+			return config, fmt.Errorf("invalid mock options: invalid latency: %w", err)
+		}
+	}
+
+	if *forwardProxyLatency != "" {
+		if config.ForwardProxy.Latency, err = ParseValidLatency(*forwardProxyLatency); err != nil {
+			return config, fmt.Errorf("invalid forward proxy options: invalid latency: %w", err)
+		}
+	}
+
+	if err = config.Connection.Validate(); err != nil {
+		return config, fmt.Errorf("invalid connection options: %w", err)
+	}
+
+	if err = config.RateLimit.Validate(); err != nil {
+		return config, fmt.Errorf("invalid rate limit options: %w", err)
+	}
+
+	if config.Store.Capacity < 0 {
+		return config, errors.New("invalid store options: capacity cannot be negative")
+	}
+
+	if err = config.Workers.Validate(); err != nil {
+		return config, fmt.Errorf("invalid worker pool options: %w", err)
+	}
+
+	if err = config.Queueing.Validate(); err != nil {
+		return config, fmt.Errorf("invalid queueing options: %w", err)
+	}
+
+	if err = config.Admin.Validate(); err != nil {
+		return config, fmt.Errorf("invalid admin options: %w", err)
+	}
+
+	if err = config.Capture.Validate(); err != nil {
+		return config, fmt.Errorf("invalid capture options: %w", err)
+	}
+
+	if config.HARFile != "" && config.Capture.Size == 0 {
+		return config, errors.New("invalid capture options: HAR file requires a positive capture size")
+	}
+
+	if err = config.Replay.Validate(); err != nil {
+		return config, fmt.Errorf("invalid replay options: %w", err)
+	}
+
+	if err = config.Mock.Behaviour.Validate(); err != nil {
+		return config, fmt.Errorf("invalid mock options: %w", err)
+	}
+
+	if err = config.Proxy.Validate(); err != nil {
+		return config, fmt.Errorf("invalid proxy options: %w", err)
+	}
+
+	if err = config.TCPProxy.Validate(); err != nil {
+		return config, fmt.Errorf("invalid TCP proxy options: %w", err)
+	}
+
+	if err = config.ForwardProxy.Validate(); err != nil {
+		return config, fmt.Errorf("invalid forward proxy options: %w", err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "no arguments should use the defaults",
+			args: []string{},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "connection arguments should be parsed",
+			args: []string{"-addr", ":8080", "-conn-max-requests", "10", "-conn-max-age", "1m", "-conn-idle-timeout", "5s"},
+			want: Config{
+				Addr:    ":8080",
+				Admin:   AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture: CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:    DefaultMockOptions(),
+				Connection: ConnectionOptions{
+					MaxRequests: 10,
+					MaxAge:      time.Minute,
+					IdleTimeout: 5 * time.Second,
+				},
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "rate limit arguments should be parsed",
+			args: []string{"-ratelimit-limit", "100", "-ratelimit-window", "1m", "-ratelimit-algorithm", "sliding-window", "-ratelimit-key", "header:X-Tenant"},
+			want: Config{
+				Addr:    DefaultAddr,
+				Admin:   AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture: CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:    DefaultMockOptions(),
+				RateLimit: RateLimitOptions{
+					Limit:     100,
+					Window:    time.Minute,
+					Algorithm: SlidingWindow,
+					Key:       "header:X-Tenant",
+				},
+				Replay:  DefaultReplayOptions(),
+				Store:   StoreOptions{Latencies: StoreLatencies{}},
+				Workers: DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "store arguments should be parsed",
+			args: []string{"-store-capacity", "100", "-store-latency", "create=10ms,read=1ms-5ms"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store: StoreOptions{
+					Capacity: 100,
+					Latencies: StoreLatencies{
+						StoreCreate: {LowerBound: 10 * time.Millisecond},
+						StoreRead:   {LowerBound: time.Millisecond, UpperBound: 5 * time.Millisecond},
+					},
+				},
+				Workers: DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "root admin prefix with admin address should be parsed",
+			args: []string{"-admin-addr", ":3435", "-admin-prefix", "/"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Addr: ":3435", Prefix: "/"},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "admin arguments should be parsed",
+			args: []string{"-admin-addr", ":3435", "-admin-prefix", "/_lhotse"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Addr: ":3435", Prefix: "/_lhotse"},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "capture arguments should be parsed",
+			args: []string{"-capture-size", "10", "-capture-body-preview", "64"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: 10, BodyPreview: 64},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "routes argument should be parsed",
+			args: []string{"-routes", "routes.yaml"},
+			want: Config{
+				Addr:       DefaultAddr,
+				Admin:      AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:    CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:       DefaultMockOptions(),
+				RateLimit:  DefaultRateLimitOptions(),
+				Replay:     DefaultReplayOptions(),
+				RoutesFile: "routes.yaml",
+				Store:      StoreOptions{Latencies: StoreLatencies{}},
+				Workers:    DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "worker pool arguments should be parsed",
+			args: []string{"-workers", "8", "-worker-queue-depth", "16", "-worker-queue-timeout", "2s", "-worker-reject-status", "429", "-worker-drop"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   WorkerPoolOptions{Workers: 8, QueueDepth: 16, QueueTimeout: 2 * time.Second, RejectStatus: 429, Drop: true},
+			},
+		},
+		{
+			name: "queueing arguments should be parsed",
+			args: []string{"-queueing-workers", "4", "-queueing-service-rate", "20"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				Queueing:  QueueingOptions{Workers: 4, ServiceRate: 20},
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "schedule argument should be parsed",
+			args: []string{"-schedule", "schedule.yaml"},
+			want: Config{
+				Addr:         DefaultAddr,
+				Admin:        AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:      CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:         DefaultMockOptions(),
+				RateLimit:    DefaultRateLimitOptions(),
+				Replay:       DefaultReplayOptions(),
+				ScheduleFile: "schedule.yaml",
+				Store:        StoreOptions{Latencies: StoreLatencies{}},
+				Workers:      DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "HAR arguments should be parsed",
+			args: []string{"-capture-size", "1000", "-capture-response-body", "-har-file", "lhotse.har"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: 1000, BodyPreview: DefaultCaptureBodyPreview, ResponseBody: true},
+				Mock:      DefaultMockOptions(),
+				HARFile:   "lhotse.har",
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "replay arguments should be parsed",
+			args: []string{"-replay", "prod.har", "-replay-timings", "-replay-match", "body", "-replay-fallback", "404"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay: ReplayOptions{
+					File:     "prod.har",
+					Timings:  true,
+					Match:    ReplayMatchBody,
+					Fallback: "404",
+				},
+				Store:   StoreOptions{Latencies: StoreLatencies{}},
+				Workers: DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "mock arguments should be parsed",
+			args: []string{"-mock", "petstore.yaml", "-mock-validate=false", "-mock-latency", "10ms-20ms", "-mock-error-rate", "0.1", "-mock-error-status", "503"},
+			want: Config{
+				Addr:    DefaultAddr,
+				Admin:   AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture: CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock: MockOptions{
+					File: "petstore.yaml",
+					Behaviour: MockBehaviour{
+						Latency:     Latency{LowerBound: 10 * time.Millisecond, UpperBound: 20 * time.Millisecond},
+						ErrorRate:   0.1,
+						ErrorStatus: http.StatusServiceUnavailable,
+					},
+				},
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "listen arguments should be parsed",
+			args: []string{"-listen", ":8080", "-listen", "unix:///tmp/lhotse.sock?error_rate=0.5"},
+			want: Config{
+				Addr:    DefaultAddr,
+				Admin:   AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture: CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Listeners: Listeners{
+					{Network: "tcp", Addr: ":8080"},
+					{Network: "unix", Addr: "/tmp/lhotse.sock", Profile: ListenerProfile{ErrorRate: 0.5}},
+				},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "proxy arguments should be parsed",
+			args: []string{"-proxy-upstream", "http://localhost:8000", "-proxy-rules", "faults.yaml"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				Proxy:     ProxyOptions{Upstream: "http://localhost:8000", RulesFile: "faults.yaml"},
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "forward proxy arguments should be parsed",
+			args: []string{"-forward-proxy-addr", ":3128", "-forward-proxy-auth", "k6:secret", "-forward-proxy-latency", "10ms-50ms"},
+			want: Config{
+				Addr:    DefaultAddr,
+				Admin:   AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture: CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				ForwardProxy: ForwardProxyOptions{
+					Addr:    ":3128",
+					Auth:    "k6:secret",
+					Latency: Latency{LowerBound: 10 * time.Millisecond, UpperBound: 50 * time.Millisecond},
+				},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name: "TCP proxy arguments should be parsed",
+			args: []string{"-tcp-proxy-addr", ":6380", "-tcp-proxy-upstream", "localhost:6379"},
+			want: Config{
+				Addr:      DefaultAddr,
+				Admin:     AdminOptions{Prefix: DefaultAdminPrefix},
+				Capture:   CaptureOptions{Size: DefaultCaptureSize, BodyPreview: DefaultCaptureBodyPreview},
+				Mock:      DefaultMockOptions(),
+				RateLimit: DefaultRateLimitOptions(),
+				Replay:    DefaultReplayOptions(),
+				Store:     StoreOptions{Latencies: StoreLatencies{}},
+				TCPProxy:  TCPProxyOptions{Addr: ":6380", Upstream: "localhost:6379"},
+				Workers:   DefaultWorkerPoolOptions(),
+			},
+		},
+		{
+			name:    "negative connection arguments should fail",
+			args:    []string{"-conn-max-requests", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported listener network should fail",
+			args:    []string{"-listen", "udp://:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported rate limit algorithm should fail",
+			args:    []string{"-ratelimit-algorithm", "leaky-bucket"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown store operation should fail",
+			args:    []string{"-store-latency", "truncate=10ms"},
+			wantErr: true,
+		},
+		{
+			name:    "relative admin prefix should fail",
+			args:    []string{"-admin-prefix", "admin"},
+			wantErr: true,
+		},
+		{
+			name:    "root admin prefix without admin address should fail",
+			args:    []string{"-admin-prefix", "/"},
+			wantErr: true,
+		},
+		{
+			name:    "negative capture size should fail",
+			args:    []string{"-capture-size", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "HAR file without capture should fail",
+			args:    []string{"-har-file", "run.har"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid replay fallback should fail",
+			args:    []string{"-replay-fallback", "nothing"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid mock error rate should fail",
+			args:    []string{"-mock-error-rate", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "negative worker queue depth should fail",
+			args:    []string{"-workers", "8", "-worker-queue-depth", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "queueing workers without service rate should fail",
+			args:    []string{"-queueing-workers", "4"},
+			wantErr: true,
+		},
+		{
+			name:    "proxy rules without upstream should fail",
+			args:    []string{"-proxy-rules", "faults.yaml"},
+			wantErr: true,
+		},
+		{
+			name:    "TCP proxy address without upstream should fail",
+			args:    []string{"-tcp-proxy-addr", ":6380"},
+			wantErr: true,
+		},
+		{
+			name:    "forward proxy credentials without address should fail",
+			args:    []string{"-forward-proxy-auth", "k6:secret"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid forward proxy latency should fail",
+			args:    []string{"-forward-proxy-addr", ":3128", "-forward-proxy-latency", "fast"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown arguments should fail",
+			args:    []string{"-unknown"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseConfig(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
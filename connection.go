@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderConnectionID is the response header holding the identifier of
+	// the connection the request was served over.
+	HeaderConnectionID = "X-Lhotse-Connection-Id"
+
+	// HeaderConnectionRequest is the response header holding the sequence
+	// number of the request on its connection, starting at 1.
+	HeaderConnectionRequest = "X-Lhotse-Connection-Request"
+)
+
+// ErrNegativeConnectionOption is returned when a connection option is negative.
+var ErrNegativeConnectionOption = errors.New("connection options cannot be negative")
+
+// ConnectionOptions controls the lifecycle of the connections served by lhotse.
+type ConnectionOptions struct {
+	// Close forces the server to close the connection after responding.
+	Close bool
+
+	// MaxRequests caps the number of requests served over a single
+	// connection. Zero means unlimited.
+	MaxRequests int64
+
+	// MaxAge caps how long a connection is kept alive. It is checked
+	// whenever a request is served. Zero means unlimited.
+	MaxAge time.Duration
+
+	// IdleTimeout is how long an idle keep-alive connection is kept open.
+	// It can only be set server-wide. Zero means no timeout.
+	IdleTimeout time.Duration
+}
+
+// Validate checks if the ConnectionOptions struct satisfies the defined constraints.
+func (o ConnectionOptions) Validate() error {
+	if o.MaxRequests < 0 {
+		return fmt.Errorf("max requests is negative: %w", ErrNegativeConnectionOption)
+	}
+
+	if o.MaxAge < 0 {
+		return fmt.Errorf("max age is negative: %w", ErrNegativeConnectionOption)
+	}
+
+	if o.IdleTimeout < 0 {
+		return fmt.Errorf("idle timeout is negative: %w", ErrNegativeConnectionOption)
+	}
+
+	return nil
+}
+
+// WithQueryParams returns a copy of the options overridden by the
+// per-request "conn_close", "conn_max_requests" and "conn_max_age"
+// query parameters.
+func (o ConnectionOptions) WithQueryParams(params url.Values) (ConnectionOptions, error) {
+	var err error
+
+	if value := params.Get("conn_close"); value != "" {
+		if o.Close, err = strconv.ParseBool(value); err != nil {
+			return o, fmt.Errorf("failed parsing conn_close parameter: %w", err)
+		}
+	}
+
+	if value := params.Get("conn_max_requests"); value != "" {
+		if o.MaxRequests, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return o, fmt.Errorf("failed parsing conn_max_requests parameter: %w", err)
+		}
+	}
+
+	if value := params.Get("conn_max_age"); value != "" {
+		if o.MaxAge, err = time.ParseDuration(value); err != nil {
+			return o, fmt.Errorf("failed parsing conn_max_age parameter: %w", err)
+		}
+	}
+
+	return o, o.Validate()
+}
+
+// connectionState holds the bookkeeping lhotse does for each connection.
+type connectionState struct {
+	id        uint64
+	createdAt time.Time
+	requests  atomic.Int64
+}
+
+// connectionStateKey is the context key under which the connection state is stored.
+type connectionStateKey struct{}
+
+// NewConnContext returns a function suitable for use as http.Server.ConnContext.
+//
+// It assigns each new connection a unique identifier, and attaches the
+// connection state to the context of the requests served over it.
+func NewConnContext() func(ctx context.Context, conn net.Conn) context.Context {
+	var lastID atomic.Uint64
+
+	return func(ctx context.Context, _ net.Conn) context.Context {
+		state := &connectionState{
+			id:        lastID.Add(1),
+			createdAt: time.Now(),
+		}
+
+		return context.WithValue(ctx, connectionStateKey{}, state)
+	}
+}
+
+// ConnectionMiddleware returns a middleware applying the connection lifecycle options.
+//
+// It adds the connection identifier and the request sequence number to every
+// response, and asks net/http to close the connection, using the Connection
+// header, once the options say it should not be reused.
+//
+// Requests with invalid overrides are answered with a 400, unless handedOver
+// returns true for them: requests handed over to another service, such as an
+// upstream or a mocked operation, may use the same query parameter names for
+// their own purpose, and keep the options as they are.
+func ConnectionMiddleware(options ConnectionOptions, handedOver func(echo.Context) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			state, ok := ctx.Request().Context().Value(connectionStateKey{}).(*connectionState)
+			if !ok {
+				return next(ctx)
+			}
+
+			requestOptions, err := options.WithQueryParams(ctx.QueryParams())
+			if err != nil {
+				if !handedOver(ctx) {
+					return ctx.String(http.StatusBadRequest, err.Error())
+				}
+				requestOptions = options
+			}
+
+			sequence := state.requests.Add(1)
+
+			header := ctx.Response().Header()
+			header.Set(HeaderConnectionID, strconv.FormatUint(state.id, 10))
+			header.Set(HeaderConnectionRequest, strconv.FormatInt(sequence, 10))
+
+			if requestOptions.shouldClose(state, sequence) {
+				header.Set(echo.HeaderConnection, "close")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// shouldClose returns true if the connection should be closed after serving
+// the request with the given sequence number.
+func (o ConnectionOptions) shouldClose(state *connectionState, sequence int64) bool {
+	if o.Close {
+		return true
+	}
+
+	if o.MaxRequests > 0 && sequence >= o.MaxRequests {
+		return true
+	}
+
+	if o.MaxAge > 0 && time.Since(state.createdAt) >= o.MaxAge {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionOptions_WithQueryParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		query   string
+		want    ConnectionOptions
+		wantErr bool
+	}{
+		{
+			name:  "no parameters should keep the options",
+			query: "",
+			want:  ConnectionOptions{MaxRequests: 10},
+		},
+		{
+			name:  "close parameter should force closing",
+			query: "conn_close=true",
+			want:  ConnectionOptions{Close: true, MaxRequests: 10},
+		},
+		{
+			name:  "max_requests and max_age parameters should override the options",
+			query: "conn_max_requests=2&conn_max_age=1s",
+			want:  ConnectionOptions{MaxRequests: 2, MaxAge: time.Second},
+		},
+		{
+			name:    "invalid close parameter should fail",
+			query:   "conn_close=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "negative max_requests parameter should fail",
+			query:   "conn_max_requests=-1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			got, err := ConnectionOptions{MaxRequests: 10}.WithQueryParams(params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConnectionMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options ConnectionOptions
+		query   string
+		wantIDs [3]string
+		wantSeq [3]string
+	}{
+		{
+			name:    "connections should be reused by default",
+			wantIDs: [3]string{"1", "1", "1"},
+			wantSeq: [3]string{"1", "2", "3"},
+		},
+		{
+			name:    "close option should close every connection",
+			options: ConnectionOptions{Close: true},
+			wantIDs: [3]string{"1", "2", "3"},
+			wantSeq: [3]string{"1", "1", "1"},
+		},
+		{
+			name:    "max requests option should cap requests per connection",
+			options: ConnectionOptions{MaxRequests: 2},
+			wantIDs: [3]string{"1", "1", "2"},
+			wantSeq: [3]string{"1", "2", "1"},
+		},
+		{
+			name:    "close query parameter should close the connection",
+			query:   "?conn_close=true",
+			wantIDs: [3]string{"1", "2", "3"},
+			wantSeq: [3]string{"1", "1", "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			e.Use(ConnectionMiddleware(tt.options, func(echo.Context) bool { return false }))
+			e.GET("/", func(ctx echo.Context) error {
+				return ctx.NoContent(http.StatusNoContent)
+			})
+
+			server := httptest.NewUnstartedServer(e)
+			server.Config.ConnContext = NewConnContext()
+			server.Start()
+			defer server.Close()
+
+			client := server.Client()
+			for i := 0; i < 3; i++ {
+				req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/"+tt.query, nil)
+				require.NoError(t, err)
+
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+
+				assert.Equal(t, tt.wantIDs[i], resp.Header.Get(HeaderConnectionID))
+				assert.Equal(t, tt.wantSeq[i], resp.Header.Get(HeaderConnectionRequest))
+			}
+		})
+	}
+}
+
+func TestConnectionMiddleware_InvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.Use(ConnectionMiddleware(ConnectionOptions{}, func(ctx echo.Context) bool {
+		return ctx.Path() == "/upstream"
+	}))
+	noContent := func(ctx echo.Context) error { return ctx.NoContent(http.StatusNoContent) }
+	e.GET("/", noContent)
+	e.GET("/upstream", noContent)
+
+	server := httptest.NewUnstartedServer(e)
+	server.Config.ConnContext = NewConnContext()
+	server.Start()
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"invalid override should fail", "/?conn_max_age=soon", http.StatusBadRequest},
+		{"invalid override of a request handed over should be left untouched", "/upstream?conn_max_age=soon", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+tt.target, nil)
+		require.NoError(t, err)
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, tt.wantStatus, resp.StatusCode, tt.name)
+	}
+}
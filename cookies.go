@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrInvalidCookieCount is returned when the number of cookies to set is not positive.
+var ErrInvalidCookieCount = errors.New("cookie count must be positive")
+
+// GetCookies is a handler returning the cookies sent by the client.
+//
+// The response is a JSON object holding the cookies' values indexed by their names.
+func (s *ServerImpl) GetCookies(ctx echo.Context) error {
+	cookies := Cookies{}
+	for _, cookie := range ctx.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	return ctx.JSON(http.StatusOK, cookies)
+}
+
+// GetCookiesSet is a handler setting cookies with the requested attributes.
+//
+// When a count greater than one is requested, the cookies' names are suffixed
+// with their index. When a size is requested, the cookies' values are generated
+// payloads of that size.
+//
+// The response is a JSON object holding the values of the cookies that were set
+// indexed by their names, or a redirect to the requested location.
+func (s *ServerImpl) GetCookiesSet(ctx echo.Context, params GetCookiesSetParams) error {
+	template, err := newCookie(params)
+	if err != nil {
+		slog.Error(
+			"failed parsing cookie attributes",
+			"handler", "GetCookiesSet",
+			"name", params.Name,
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	count := 1
+	if params.Count != nil {
+		count = *params.Count
+	}
+
+	if count < 1 {
+		return ctx.String(http.StatusBadRequest, ErrInvalidCookieCount.Error())
+	}
+
+	var size *Size
+	if params.Size != nil {
+		parsed, parseErr := ParseSize(*params.Size)
+		if parseErr == nil {
+			parseErr = parsed.Validate()
+		}
+
+		if parseErr != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("invalid cookie size: %s", parseErr))
+		}
+
+		size = &parsed
+	}
+
+	cookies := Cookies{}
+	for i := 1; i <= count; i++ {
+		cookie := *template
+
+		if count > 1 {
+			cookie.Name = fmt.Sprintf("%s_%d", template.Name, i)
+		}
+
+		if size != nil {
+			cookie.Value = string(size.Payload())
+		}
+
+		ctx.SetCookie(&cookie)
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	if params.Redirect != nil {
+		return ctx.Redirect(http.StatusFound, *params.Redirect)
+	}
+
+	return ctx.JSON(http.StatusOK, cookies)
+}
+
+// GetCookiesDelete is a handler deleting the cookies with the requested names.
+//
+// Cookies are deleted by setting them again, empty and already expired. The
+// domain and path attributes must match the ones of the cookies to delete.
+func (s *ServerImpl) GetCookiesDelete(ctx echo.Context, params GetCookiesDeleteParams) error {
+	cookies := Cookies{}
+	for _, name := range params.Name {
+		cookie := &http.Cookie{
+			Name:    name,
+			Expires: time.Unix(0, 0),
+			MaxAge:  -1,
+		}
+
+		if params.Domain != nil {
+			cookie.Domain = *params.Domain
+		}
+
+		if params.Path != nil {
+			cookie.Path = *params.Path
+		}
+
+		ctx.SetCookie(cookie)
+		cookies[name] = ""
+	}
+
+	if params.Redirect != nil {
+		return ctx.Redirect(http.StatusFound, *params.Redirect)
+	}
+
+	return ctx.JSON(http.StatusOK, cookies)
+}
+
+// newCookie builds the cookie described by the parameters.
+func newCookie(params GetCookiesSetParams) (*http.Cookie, error) {
+	cookie := &http.Cookie{Name: params.Name}
+
+	if params.Value != nil {
+		cookie.Value = *params.Value
+	}
+
+	if params.Domain != nil {
+		cookie.Domain = *params.Domain
+	}
+
+	if params.Path != nil {
+		cookie.Path = *params.Path
+	}
+
+	if params.Expires != nil {
+		expires, err := parseCookieExpires(*params.Expires)
+		if err != nil {
+			return nil, err
+		}
+
+		cookie.Expires = expires
+	}
+
+	if params.MaxAge != nil {
+		cookie.MaxAge = *params.MaxAge
+
+		// net/http omits the Max-Age attribute when it is zero,
+		// and represents Max-Age=0 as a negative value instead.
+		if cookie.MaxAge == 0 {
+			cookie.MaxAge = -1
+		}
+	}
+
+	if params.Secure != nil {
+		cookie.Secure = *params.Secure
+	}
+
+	if params.HttpOnly != nil {
+		cookie.HttpOnly = *params.HttpOnly
+	}
+
+	if params.SameSite != nil {
+		sameSite, err := parseSameSite(*params.SameSite)
+		if err != nil {
+			return nil, err
+		}
+
+		cookie.SameSite = sameSite
+	}
+
+	if err := cookie.Valid(); err != nil {
+		return nil, fmt.Errorf("invalid cookie: %w", err)
+	}
+
+	return cookie, nil
+}
+
+// parseCookieExpires parses the expiration date of a cookie, expressed either
+// as an HTTP date, or as a duration from now.
+func parseCookieExpires(expires string) (time.Time, error) {
+	if duration, err := time.ParseDuration(expires); err == nil {
+		return time.Now().Add(duration), nil
+	}
+
+	date, err := http.ParseTime(expires)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed parsing expires %q as a duration or an HTTP date", expires)
+	}
+
+	return date, nil
+}
+
+// parseSameSite parses the SameSite attribute of a cookie.
+func parseSameSite(sameSite string) (http.SameSite, error) {
+	switch strings.ToLower(sameSite) {
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return http.SameSiteDefaultMode, fmt.Errorf("unsupported same site attribute %q", sameSite)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCookies(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/cookies", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := &ServerImpl{}
+
+	assert.NoError(t, handler.GetCookies(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"session":"abc","theme":"dark"}`, rec.Body.String())
+}
+
+func TestGetCookiesSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantCookies []string
+	}{
+		{
+			name:        "setting a cookie should succeed",
+			query:       "name=session&value=abc",
+			wantStatus:  http.StatusOK,
+			wantCookies: []string{"session=abc"},
+		},
+		{
+			name:       "setting a cookie with attributes should succeed",
+			query:      "name=session&value=abc&domain=example.com&path=/api&max_age=60&secure=true&http_only=true&same_site=strict",
+			wantStatus: http.StatusOK,
+			wantCookies: []string{
+				"session=abc; Path=/api; Domain=example.com; Max-Age=60; HttpOnly; Secure; SameSite=Strict",
+			},
+		},
+		{
+			name:        "setting many cookies should suffix their names",
+			query:       "name=session&value=abc&count=2",
+			wantStatus:  http.StatusOK,
+			wantCookies: []string{"session_1=abc", "session_2=abc"},
+		},
+		{
+			name:        "setting a cookie with a redirect should redirect",
+			query:       "name=session&value=abc&redirect=/cookies",
+			wantStatus:  http.StatusFound,
+			wantCookies: []string{"session=abc"},
+		},
+		{
+			name:       "invalid same site attribute should fail",
+			query:      "name=session&same_site=sometimes",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid count should fail",
+			query:      "name=session&count=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid size should fail",
+			query:      "name=session&size=invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			RegisterHandlers(e, &ServerImpl{})
+			req := httptest.NewRequest(http.MethodGet, "/cookies/set?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantCookies, rec.Header().Values(echo.HeaderSetCookie))
+		})
+	}
+}
+
+func TestGetCookiesSetSize(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, &ServerImpl{})
+	req := httptest.NewRequest(http.MethodGet, "/cookies/set?name=large&size=4kb", nil)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Len(t, cookies[0].Value, int(4*Kilobyte))
+}
+
+func TestGetCookiesDelete(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, &ServerImpl{})
+	req := httptest.NewRequest(http.MethodGet, "/cookies/delete?name=session&name=theme&path=/", nil)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{
+		"session=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0",
+		"theme=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0",
+	}, rec.Header().Values(echo.HeaderSetCookie))
+}
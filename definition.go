@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ResponseDefinition describes a response served by lhotse, rather than
+// computed by one of its handlers.
+type ResponseDefinition struct {
+	// Status is the status of the response. Defaults to 200.
+	Status int `json:"status,omitempty" yaml:"status,omitempty"`
+
+	// Headers are set on the response.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// Body is the body of the response.
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+
+	// Template renders the body as a Go text/template, executed with the
+	// request's TemplateData.
+	Template bool `json:"template,omitempty" yaml:"template,omitempty"`
+
+	// ContentType is the content type of the response. Defaults to
+	// text/plain, or application/octet-stream when Size is set.
+	ContentType string `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+
+	// Latency is waited for before responding.
+	Latency Latency `json:"latency" yaml:"latency"`
+
+	// Size, when set, replaces the body with a payload of that size, as
+	// the /data/{size} endpoint does.
+	Size *Size `json:"size,omitempty" yaml:"size,omitempty"`
+
+	template *template.Template
+}
+
+// Validate checks if the ResponseDefinition struct satisfies the defined constraints.
+func (d ResponseDefinition) Validate() error {
+	if d.Status != 0 && (d.Status < 100 || d.Status > 599) {
+		return errors.New("status must be between 100 and 599")
+	}
+
+	if err := d.Latency.Validate(); err != nil {
+		return err
+	}
+
+	if d.Template {
+		if _, err := ParseResponseTemplate(d.Body); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+
+	if d.Size != nil {
+		return d.Size.Validate()
+	}
+
+	return nil
+}
+
+// Compile validates the definition, and parses its body template, if any,
+// once and for all. It should be called before Write.
+func (d *ResponseDefinition) Compile() error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	d.template = nil
+	if d.Template {
+		tmpl, err := ParseResponseTemplate(d.Body)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+
+		d.template = tmpl
+	}
+
+	return nil
+}
+
+// Write waits for the definition's latency, and writes the response it describes.
+func (d ResponseDefinition) Write(ctx echo.Context) error {
+	d.Latency.Wait()
+
+	status := d.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	for name, value := range d.Headers {
+		ctx.Response().Header().Set(name, value)
+	}
+
+	body := []byte(d.Body)
+	contentType := echo.MIMETextPlain
+	if d.Template {
+		tmpl, err := d.bodyTemplate()
+		if err != nil {
+			return err
+		}
+
+		if body, err = ExecuteResponseTemplate(ctx, tmpl); err != nil {
+			return err
+		}
+	}
+	if d.Size != nil {
+		body = d.Size.Payload()
+		contentType = echo.MIMEOctetStream
+	}
+
+	if d.ContentType != "" {
+		contentType = d.ContentType
+	}
+
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		return ctx.NoContent(status)
+	}
+
+	return ctx.Blob(status, contentType, body)
+}
+
+// bodyTemplate returns the body template, parsed once and for all if the
+// definition was compiled, and on every call otherwise.
+func (d ResponseDefinition) bodyTemplate() (*template.Template, error) {
+	if d.template != nil {
+		return d.template, nil
+	}
+
+	return ParseResponseTemplate(d.Body)
+}
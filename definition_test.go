@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseDefinition_Write(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		definition      ResponseDefinition
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantBodySize    int
+	}{
+		{
+			name:            "empty definition should respond with an empty 200",
+			definition:      ResponseDefinition{},
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMETextPlain,
+		},
+		{
+			name:            "body and content type should be written",
+			definition:      ResponseDefinition{Status: http.StatusCreated, Body: `{"a":1}`, ContentType: echo.MIMEApplicationJSON},
+			wantStatus:      http.StatusCreated,
+			wantContentType: echo.MIMEApplicationJSON,
+			wantBody:        `{"a":1}`,
+		},
+		{
+			name:            "template body should be rendered with the request",
+			definition:      ResponseDefinition{Body: `hello {{ .Query.name }}`, Template: true},
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMETextPlain,
+			wantBody:        "hello alice",
+		},
+		{
+			name:            "size should replace the body with a payload",
+			definition:      ResponseDefinition{Body: "ignored", Size: &Size{LowerBound: 64}},
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMEOctetStream,
+			wantBodySize:    64,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/?name=alice", nil), rec)
+
+			assert.NoError(t, tt.definition.Write(ctx))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get(echo.HeaderContentType))
+			if tt.wantBodySize > 0 {
+				assert.Equal(t, tt.wantBodySize, rec.Body.Len())
+			} else {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestResponseDefinition_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ResponseDefinition{Body: "{{ .Path }}", Template: true}.Validate())
+	assert.NoError(t, ResponseDefinition{Body: "{{ .Path"}.Validate(), "non template body should not be parsed")
+	assert.Error(t, ResponseDefinition{Body: "{{ .Path", Template: true}.Validate())
+	assert.Error(t, ResponseDefinition{Status: 600}.Validate())
+}
+
+func TestResponseDefinition_Compile(t *testing.T) {
+	t.Parallel()
+
+	invalid := ResponseDefinition{Body: "{{ .Path", Template: true}
+	assert.Error(t, invalid.Compile())
+
+	definition := ResponseDefinition{Body: "hello {{ .Query.name }}", Template: true}
+	require.NoError(t, definition.Compile())
+	require.NotNil(t, definition.template)
+
+	// Writing should execute the compiled template, rather than parse the body again
+	definition.Body = "{{ .Path"
+
+	rec := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/?name=alice", nil), rec)
+
+	require.NoError(t, definition.Write(ctx))
+	assert.Equal(t, "hello alice", rec.Body.String())
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ForwardProxyDialTimeout is how long the forward proxy waits for the
+// targets of tunnels to accept a connection.
+const ForwardProxyDialTimeout = 10 * time.Second
+
+// ForwardProxyReadHeaderTimeout is how long the forward proxy waits for the
+// headers of a request.
+const ForwardProxyReadHeaderTimeout = 10 * time.Second
+
+// ForwardProxyRecordsSize is the number of most recent requests the forward
+// proxy records.
+const ForwardProxyRecordsSize = 1000
+
+// ErrForwardProxyDisabled is returned when the forward proxy mode is disabled.
+var ErrForwardProxyDisabled = errors.New("forward proxy mode is disabled")
+
+// ForwardProxyOptions holds the options of the forward proxy mode.
+type ForwardProxyOptions struct {
+	// Addr is the address the forward proxy listens on. An empty address
+	// disables the forward proxy mode.
+	Addr string
+
+	// Auth holds the "username:password" credentials clients must provide
+	// through the Proxy-Authorization header. Empty credentials let any
+	// client through.
+	Auth string
+
+	// Latency is waited for before establishing each tunnel, and before
+	// forwarding each request outside of a tunnel.
+	Latency Latency
+}
+
+// Enabled returns true if the forward proxy mode applies.
+func (o ForwardProxyOptions) Enabled() bool {
+	return o.Addr != ""
+}
+
+// Validate checks if the ForwardProxyOptions struct satisfies the defined constraints.
+func (o ForwardProxyOptions) Validate() error {
+	if !o.Enabled() {
+		if o.Auth != "" || o.Latency != (Latency{}) {
+			return errors.New("credentials and latency require an address")
+		}
+
+		return nil
+	}
+
+	if o.Auth != "" && !strings.Contains(o.Auth, ":") {
+		return errors.New(`credentials must be in the "username:password" format`)
+	}
+
+	if err := o.Latency.Validate(); err != nil {
+		return fmt.Errorf("invalid latency: %w", err)
+	}
+
+	return nil
+}
+
+// ForwardProxyRecord describes a request received by the forward proxy.
+type ForwardProxyRecord struct {
+	// ID identifies the record. IDs are assigned in order, starting at 1.
+	ID int64 `json:"id"`
+
+	// Time is when the request was received.
+	Time time.Time `json:"time"`
+
+	// Method is the method of the request, CONNECT for tunnels.
+	Method string `json:"method"`
+
+	// Target is the URL the request is forwarded to, or the address the
+	// tunnel is established with.
+	Target string `json:"target"`
+
+	// Tunnel is true for CONNECT requests.
+	Tunnel bool `json:"tunnel"`
+
+	// RemoteAddr is the address of the client.
+	RemoteAddr string `json:"remote_addr"`
+
+	// Username is the username the client authenticated with, if any.
+	Username string `json:"username,omitempty"`
+
+	// Status is the status the forward proxy, or the upstream, responded with.
+	Status int `json:"status"`
+}
+
+// ForwardProxy is an HTTP proxy forwarding absolute-URI requests, and
+// tunnelling CONNECT ones, to the servers they target, and recording the
+// requests it receives.
+//
+// It is safe for concurrent use.
+type ForwardProxy struct {
+	options ForwardProxyOptions
+	handler *httputil.ReverseProxy
+	server  *http.Server
+
+	mu      sync.Mutex
+	records []ForwardProxyRecord
+	lastID  int64
+	tunnels map[net.Conn]struct{}
+}
+
+// NewForwardProxy creates a new ForwardProxy instance, with no records.
+func NewForwardProxy(options ForwardProxyOptions) *ForwardProxy {
+	// Requests are forwarded to the servers they target directly, whatever
+	// the proxy settings of the environment.
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.Proxy = nil
+
+	proxy := &ForwardProxy{
+		options: options,
+		tunnels: make(map[net.Conn]struct{}),
+	}
+	proxy.handler = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.Out.URL = r.In.URL
+			r.Out.Host = r.In.URL.Host
+		},
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.Error(
+				"Failed to forward request",
+				"handler", "ForwardProxy",
+				"target", r.URL.String(),
+				"error_message", err.Error(),
+			)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxy.server = &http.Server{Handler: proxy, ReadHeaderTimeout: ForwardProxyReadHeaderTimeout}
+
+	return proxy
+}
+
+// Records returns the requests received by the forward proxy, from the
+// oldest to the most recent.
+func (p *ForwardProxy) Records() []ForwardProxyRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]ForwardProxyRecord{}, p.records...)
+}
+
+// ClearRecords removes every record.
+func (p *ForwardProxy) ClearRecords() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.records = nil
+}
+
+// record records the request, evicting the oldest record if there are
+// already ForwardProxyRecordsSize of them.
+func (p *ForwardProxy) record(record ForwardProxyRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastID++
+	record.ID = p.lastID
+
+	if len(p.records) == ForwardProxyRecordsSize {
+		p.records = append(p.records[:0], p.records[1:]...)
+	}
+	p.records = append(p.records, record)
+}
+
+// Serve accepts connections on the listener, and handles the requests they
+// carry, until the proxy is shut down.
+func (p *ForwardProxy) Serve(listener net.Listener) error {
+	if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown gracefully shuts the forward proxy down, closing the tunnels it
+// established.
+func (p *ForwardProxy) Shutdown(ctx context.Context) error {
+	err := p.server.Shutdown(ctx)
+
+	p.mu.Lock()
+	for conn := range p.tunnels {
+		_ = conn.Close()
+	}
+	p.tunnels = nil
+	p.mu.Unlock()
+
+	return err
+}
+
+// ServeHTTP implements http.Handler, tunnelling CONNECT requests, and
+// forwarding absolute-URI ones.
+func (p *ForwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tunnel := r.Method == http.MethodConnect
+	record := ForwardProxyRecord{
+		Time:       time.Now(),
+		Method:     r.Method,
+		Target:     r.URL.String(),
+		Tunnel:     tunnel,
+		RemoteAddr: r.RemoteAddr,
+	}
+	if tunnel {
+		record.Target = r.Host
+	}
+
+	username, ok := p.authenticate(r)
+	record.Username = username
+	if !ok {
+		record.Status = http.StatusProxyAuthRequired
+		p.record(record)
+
+		w.Header().Set("Proxy-Authenticate", `Basic realm="lhotse"`)
+		http.Error(w, http.StatusText(http.StatusProxyAuthRequired), http.StatusProxyAuthRequired)
+
+		return
+	}
+
+	if !tunnel && (!r.URL.IsAbs() || r.URL.Host == "") {
+		record.Status = http.StatusBadRequest
+		p.record(record)
+
+		http.Error(w, "forward proxy requests must target an absolute URI", http.StatusBadRequest)
+
+		return
+	}
+
+	p.options.Latency.Wait()
+
+	if tunnel {
+		record.Status = p.tunnel(w, r)
+		p.record(record)
+
+		return
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	p.handler.ServeHTTP(recorder, r)
+
+	record.Status = recorder.status
+	p.record(record)
+}
+
+// authenticate checks the request's Proxy-Authorization header against the
+// credentials, and returns the username it holds.
+func (p *ForwardProxy) authenticate(r *http.Request) (string, bool) {
+	header := r.Header.Get("Proxy-Authorization")
+	username, password, hasAuth := (&http.Request{Header: http.Header{"Authorization": {header}}}).BasicAuth()
+	if p.options.Auth == "" {
+		return username, true
+	}
+
+	if !hasAuth {
+		return "", false
+	}
+
+	return username, subtle.ConstantTimeCompare([]byte(username+":"+password), []byte(p.options.Auth)) == 1
+}
+
+// tunnel establishes a tunnel between the client and the address the
+// CONNECT request targets, and returns the status it responded with.
+//
+// The response to the CONNECT request is sent once the target accepted the
+// connection, and the tunnel is then piped until either end closes it.
+func (p *ForwardProxy) tunnel(w http.ResponseWriter, r *http.Request) int {
+	target, err := net.DialTimeout("tcp", r.Host, ForwardProxyDialTimeout)
+	if err != nil {
+		slog.Error(
+			"failed connecting to tunnel target",
+			"handler", "ForwardProxy",
+			"target", r.Host,
+			"error_message", err.Error(),
+		)
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+
+		return http.StatusBadGateway
+	}
+
+	client, buffered, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		_ = target.Close()
+		http.Error(w, "tunnelling is not supported over this connection", http.StatusBadGateway)
+
+		return http.StatusBadGateway
+	}
+
+	if !p.track(client, target) {
+		_ = client.Close()
+		_ = target.Close()
+
+		return http.StatusServiceUnavailable
+	}
+
+	if _, err = io.WriteString(client, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		p.untrack(client, target)
+		return http.StatusOK
+	}
+
+	go func() {
+		defer p.untrack(client, target)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = io.Copy(target, buffered)
+			closeWrite(target)
+		}()
+
+		_, _ = io.Copy(client, target)
+		closeWrite(client)
+		<-done
+	}()
+
+	return http.StatusOK
+}
+
+// track registers the tunnel's connections for them to be closed along with
+// the proxy, and returns false if the proxy is already shut down.
+func (p *ForwardProxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tunnels == nil {
+		return false
+	}
+
+	for _, conn := range conns {
+		p.tunnels[conn] = struct{}{}
+	}
+
+	return true
+}
+
+// untrack forgets about the tunnel's connections, and closes them.
+func (p *ForwardProxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	for _, conn := range conns {
+		delete(p.tunnels, conn)
+	}
+	p.mu.Unlock()
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+// statusRecorder is an http.ResponseWriter recording the status it is
+// written with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status, and writes it to the underlying writer.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController to use.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardProxyOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options ForwardProxyOptions
+		wantErr bool
+	}{
+		{"disabled proxy should be valid", ForwardProxyOptions{}, false},
+		{"credentials should be valid", ForwardProxyOptions{Addr: ":3128", Auth: "k6:secret"}, false},
+		{"credentials without password should fail", ForwardProxyOptions{Addr: ":3128", Auth: "k6"}, true},
+		{"negative latency should fail", ForwardProxyOptions{Addr: ":3128", Latency: Latency{LowerBound: -time.Second}}, true},
+		{"credentials without address should fail", ForwardProxyOptions{Auth: "k6:secret"}, true},
+		{"latency without address should fail", ForwardProxyOptions{Latency: Latency{LowerBound: time.Second}}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantErr, tt.options.Validate() != nil)
+		})
+	}
+}
+
+// newTestForwardProxy returns a forward proxy with the options applied,
+// along with its URL.
+func newTestForwardProxy(t *testing.T, options ForwardProxyOptions) (*ForwardProxy, *url.URL) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	options.Addr = listener.Addr().String()
+	proxy := NewForwardProxy(options)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, proxy.Serve(listener))
+	}()
+
+	t.Cleanup(func() {
+		assert.NoError(t, proxy.Shutdown(context.Background()))
+		<-done
+	})
+
+	return proxy, &url.URL{Scheme: "http", Host: listener.Addr().String()}
+}
+
+// newForwardProxyTestClient returns a client sending its requests through
+// the forward proxy, and trusting the upstream's certificate.
+func newForwardProxyTestClient(upstream *httptest.Server, proxyURL *url.URL) *http.Client {
+	transport := &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	if upstream.TLS != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: upstream.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs} //nolint:forcetypeassert,gosec
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+func TestForwardProxy(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proxy-Authorization", r.Header.Get("Proxy-Authorization"))
+		_, _ = io.WriteString(w, "hello from "+r.URL.Path)
+	})
+	plain := httptest.NewServer(handler)
+	t.Cleanup(plain.Close)
+	secure := httptest.NewTLSServer(handler)
+	t.Cleanup(secure.Close)
+
+	tests := []struct {
+		name       string
+		upstream   *httptest.Server
+		auth       *url.Userinfo
+		wantStatus int
+		wantRecord ForwardProxyRecord
+	}{
+		{
+			name:       "absolute-URI request should be forwarded",
+			upstream:   plain,
+			auth:       url.UserPassword("k6", "secret"),
+			wantStatus: http.StatusOK,
+			wantRecord: ForwardProxyRecord{Method: http.MethodGet, Target: plain.URL + "/users", Username: "k6", Status: http.StatusOK},
+		},
+		{
+			name:       "CONNECT request should be tunnelled",
+			upstream:   secure,
+			auth:       url.UserPassword("k6", "secret"),
+			wantStatus: http.StatusOK,
+			wantRecord: ForwardProxyRecord{Method: http.MethodConnect, Target: strings.TrimPrefix(secure.URL, "https://"), Tunnel: true, Username: "k6", Status: http.StatusOK},
+		},
+		{
+			name:       "missing credentials should be rejected",
+			upstream:   plain,
+			wantStatus: http.StatusProxyAuthRequired,
+			wantRecord: ForwardProxyRecord{Method: http.MethodGet, Target: plain.URL + "/users", Status: http.StatusProxyAuthRequired},
+		},
+		{
+			name:       "invalid credentials should be rejected",
+			upstream:   plain,
+			auth:       url.UserPassword("k6", "wrong"),
+			wantStatus: http.StatusProxyAuthRequired,
+			wantRecord: ForwardProxyRecord{Method: http.MethodGet, Target: plain.URL + "/users", Username: "k6", Status: http.StatusProxyAuthRequired},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			proxy, proxyURL := newTestForwardProxy(t, ForwardProxyOptions{Auth: "k6:secret"})
+			proxyURL.User = tt.auth
+
+			res, err := newForwardProxyTestClient(tt.upstream, proxyURL).Get(tt.upstream.URL + "/users") //nolint:noctx
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "hello from /users", string(body))
+				assert.Empty(t, res.Header.Get("X-Proxy-Authorization"), "credentials should not be forwarded")
+			}
+
+			records := proxy.Records()
+			require.Len(t, records, 1)
+			assert.Equal(t, int64(1), records[0].ID)
+			assert.NotEmpty(t, records[0].RemoteAddr)
+			records[0].ID, records[0].Time, records[0].RemoteAddr = 0, time.Time{}, ""
+			assert.Equal(t, tt.wantRecord, records[0])
+		})
+	}
+}
+
+func TestForwardProxy_Latency(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(upstream.Close)
+
+	proxy, proxyURL := newTestForwardProxy(t, ForwardProxyOptions{Latency: Latency{LowerBound: 50 * time.Millisecond}})
+	client := newForwardProxyTestClient(upstream, proxyURL)
+
+	// The latency applies once per tunnel, which keep-alive connections reuse
+	for i, wantLatency := range []time.Duration{50 * time.Millisecond, 0} {
+		start := time.Now()
+		res, err := client.Get(upstream.URL) //nolint:noctx
+		require.NoError(t, err)
+		res.Body.Close()
+
+		assert.GreaterOrEqual(t, time.Since(start), wantLatency, "request %d", i)
+	}
+
+	assert.Len(t, proxy.Records(), 1, "a single tunnel should be established")
+}
+
+func TestForwardProxy_NonProxyRequest(t *testing.T) {
+	t.Parallel()
+
+	proxy, proxyURL := newTestForwardProxy(t, ForwardProxyOptions{})
+
+	res, err := http.Get(proxyURL.String() + "/users") //nolint:noctx
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Len(t, proxy.Records(), 1)
+	assert.Equal(t, http.StatusBadRequest, proxy.Records()[0].Status)
+
+	proxy.ClearRecords()
+	assert.Empty(t, proxy.Records())
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HARVersion is the version of the HTTP Archive format lhotse produces.
+const HARVersion = "1.2"
+
+// HAR is an HTTP Archive, as described by the HAR 1.2 specification.
+//
+// See http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the root of an HTTP Archive.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator describes the application which produced an HTTP Archive.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry describes a single request, and its response.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+// HARRequest describes a request.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARResponse describes a response.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARCookie describes a cookie.
+type HARCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARNameValue describes a header or query parameter.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData describes the body of a request.
+//
+// Encoding is not part of the HAR 1.2 specification: as for response
+// contents, it is "base64" when Text holds a base64-encoded binary body.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARContent describes the body of a response.
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings describes the phases of a request, in milliseconds. Phases
+// which do not apply, or are unknown to the server, are set to -1.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// NewHAR creates an HTTP Archive holding the captured requests.
+//
+// As seen from the server, a request's wait phase lasts until the server
+// starts responding, and its receive phase until the server is done
+// responding.
+func NewHAR(requests []CapturedRequest) HAR {
+	entries := make([]HAREntry, 0, len(requests))
+	for _, req := range requests {
+		entries = append(entries, newHAREntry(req))
+	}
+
+	return HAR{
+		Log: HARLog{
+			Version: HARVersion,
+			Creator: HARCreator{Name: "lhotse", Version: buildVersion()},
+			Entries: entries,
+		},
+	}
+}
+
+// newHAREntry converts a captured request into an HTTP Archive entry.
+func newHAREntry(req CapturedRequest) HAREntry {
+	scheme := "http"
+	if req.TLS {
+		scheme = "https"
+	}
+	target := url.URL{Scheme: scheme, Host: req.Host, Path: req.Path, RawQuery: req.Query}
+	responseText, responseEncoding := harText(req.ResponseBody)
+
+	entry := HAREntry{
+		StartedDateTime: req.Time,
+		Time:            req.DurationMs,
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         target.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     harCookies((&http.Request{Header: req.Headers}).Cookies()),
+			Headers:     harHeaders(req.Headers),
+			QueryString: harQuery(req.Query),
+			HeadersSize: -1,
+			BodySize:    req.BodySize,
+		},
+		Response: HARResponse{
+			Status:      req.Status,
+			StatusText:  http.StatusText(req.Status),
+			HTTPVersion: req.Proto,
+			Cookies:     harCookies((&http.Response{Header: req.ResponseHeaders}).Cookies()),
+			Headers:     harHeaders(req.ResponseHeaders),
+			Content: HARContent{
+				Size:     req.ResponseSize,
+				MimeType: req.ResponseHeaders.Get(echo.HeaderContentType),
+				Text:     responseText,
+				Encoding: responseEncoding,
+			},
+			RedirectURL: req.ResponseHeaders.Get(echo.HeaderLocation),
+			HeadersSize: -1,
+			BodySize:    req.ResponseSize,
+		},
+		Timings: HARTimings{
+			Blocked: -1,
+			DNS:     -1,
+			Connect: -1,
+			Send:    0,
+			Wait:    req.WaitMs,
+			Receive: max(req.DurationMs-req.WaitMs, 0),
+			SSL:     -1,
+		},
+	}
+
+	if req.Body != "" {
+		text, encoding := harText(req.Body)
+		entry.Request.PostData = &HARPostData{
+			MimeType: req.Headers.Get(echo.HeaderContentType),
+			Text:     text,
+			Encoding: encoding,
+		}
+	}
+
+	var truncated []string
+	if req.BodyTruncated {
+		truncated = append(truncated, "request body truncated")
+	}
+	if req.ResponseBodyTruncated {
+		truncated = append(truncated, "response body truncated")
+	}
+	entry.Comment = strings.Join(truncated, ", ")
+
+	return entry
+}
+
+// harText returns the body as HTTP Archive text, along with its encoding:
+// bodies which are not valid UTF-8 are base64-encoded.
+func harText(body string) (text string, encoding string) {
+	if utf8.ValidString(body) {
+		return body, ""
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(body)), "base64"
+}
+
+// harHeaders converts headers into sorted HTTP Archive name-value pairs.
+func harHeaders(headers http.Header) []HARNameValue {
+	pairs := make([]HARNameValue, 0, len(headers))
+	for name, values := range headers {
+		for _, value := range values {
+			pairs = append(pairs, HARNameValue{Name: name, Value: value})
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+
+	return pairs
+}
+
+// harQuery converts a raw query into HTTP Archive name-value pairs, in order.
+func harQuery(rawQuery string) []HARNameValue {
+	pairs := make([]HARNameValue, 0)
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(param, "=")
+		name, _ = url.QueryUnescape(name)
+		value, _ = url.QueryUnescape(value)
+		pairs = append(pairs, HARNameValue{Name: name, Value: value})
+	}
+
+	return pairs
+}
+
+// harCookies converts cookies into HTTP Archive cookies.
+func harCookies(cookies []*http.Cookie) []HARCookie {
+	harCookies := make([]HARCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		harCookies = append(harCookies, HARCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	return harCookies
+}
+
+// buildVersion returns the version lhotse was built as, or "devel" if unknown.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return "devel"
+	}
+
+	return info.Main.Version
+}
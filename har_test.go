@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHAR(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	har := NewHAR([]CapturedRequest{
+		{
+			Time:     started,
+			Method:   http.MethodPost,
+			Path:     "/store/users",
+			Query:    "a=1&b=two%20words",
+			Proto:    "HTTP/1.1",
+			Host:     "localhost:3434",
+			Headers:  http.Header{"Content-Type": []string{"application/json"}, "Cookie": []string{"session=abc"}},
+			Body:     `{"name":"alice"}`,
+			BodySize: 16,
+			Status:   http.StatusCreated,
+			ResponseHeaders: http.Header{
+				"Content-Type": []string{"application/json"},
+				"Set-Cookie":   []string{"seen=1; Path=/; HttpOnly"},
+			},
+			ResponseBody: `{"id":"1"}`,
+			ResponseSize: 10,
+			WaitMs:       3,
+			DurationMs:   5,
+		},
+	})
+
+	assert.Equal(t, HARVersion, har.Log.Version)
+	assert.Equal(t, "lhotse", har.Log.Creator.Name)
+	require.Len(t, har.Log.Entries, 1)
+
+	entry := har.Log.Entries[0]
+	assert.Equal(t, started, entry.StartedDateTime)
+	assert.InDelta(t, 5, entry.Time, 0.001)
+
+	assert.Equal(t, "http://localhost:3434/store/users?a=1&b=two%20words", entry.Request.URL)
+	assert.Equal(t, []HARNameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "two words"}}, entry.Request.QueryString)
+	assert.Equal(t, []HARCookie{{Name: "session", Value: "abc"}}, entry.Request.Cookies)
+	assert.Equal(t, &HARPostData{MimeType: "application/json", Text: `{"name":"alice"}`}, entry.Request.PostData)
+
+	assert.Equal(t, http.StatusCreated, entry.Response.Status)
+	assert.Equal(t, "Created", entry.Response.StatusText)
+	assert.Equal(t, []HARCookie{{Name: "seen", Value: "1"}}, entry.Response.Cookies)
+	assert.Equal(t, HARContent{Size: 10, MimeType: "application/json", Text: `{"id":"1"}`}, entry.Response.Content)
+
+	assert.Equal(t, HARTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: 3, Receive: 2, SSL: -1}, entry.Timings)
+}
+
+func TestNewHAR_Bodies(t *testing.T) {
+	t.Parallel()
+
+	har := NewHAR([]CapturedRequest{
+		{
+			Method:                http.MethodPost,
+			Path:                  "/upload",
+			Host:                  "localhost:3434",
+			TLS:                   true,
+			Headers:               http.Header{"Content-Type": []string{"application/octet-stream"}},
+			Body:                  "\xff\xfe\x00",
+			BodyTruncated:         true,
+			Status:                http.StatusOK,
+			ResponseHeaders:       http.Header{"Content-Type": []string{"image/png"}},
+			ResponseBody:          "\x89PNG",
+			ResponseBodyTruncated: true,
+			ResponseSize:          1024,
+		},
+	})
+	require.Len(t, har.Log.Entries, 1)
+
+	entry := har.Log.Entries[0]
+	assert.Equal(t, "https://localhost:3434/upload", entry.Request.URL, "TLS requests should use the https scheme")
+	assert.Equal(t, &HARPostData{MimeType: "application/octet-stream", Text: "//4A", Encoding: "base64"}, entry.Request.PostData)
+	assert.Equal(t, HARContent{Size: 1024, MimeType: "image/png", Text: "iVBORw==", Encoding: "base64"}, entry.Response.Content)
+	assert.Equal(t, "request body truncated, response body truncated", entry.Comment)
+}
@@ -44,6 +44,17 @@ func ParseLatency(duration string) (latency Latency, err error) {
 	return
 }
 
+// ParseValidLatency parses a duration string, as ParseLatency does, and
+// validates the resulting Latency.
+func ParseValidLatency(duration string) (Latency, error) {
+	latency, err := ParseLatency(duration)
+	if err != nil {
+		return latency, err
+	}
+
+	return latency, latency.Validate()
+}
+
 // Validate checks if the Latency struct satisfies the defined constraints.
 func (l Latency) Validate() error {
 	if l.LowerBound < 0 {
@@ -63,13 +74,14 @@ func (l Latency) Validate() error {
 
 // Wait waits for the duration held by the latency.
 //
-// If the latency has upper and lower bounds, it waits for a random duration
-// between the lower and upper bounds. Otherwise it waits for the lower bound.
+// If the latency has distinct upper and lower bounds, it waits for a random
+// duration between the lower and upper bounds. Otherwise it waits for the
+// lower bound.
 //
 //nolint:gosec
 func (l Latency) Wait() time.Duration {
-	// If the latency has no bounds, wait for the specified duration
-	if !l.HasBounds() {
+	// If the latency has no bounds, or equal ones, wait for the specified duration
+	if !l.HasBounds() || l.UpperBound <= l.LowerBound {
 		time.Sleep(l.LowerBound)
 		return l.LowerBound
 	}
@@ -91,6 +103,30 @@ func (l Latency) String() string {
 	return fmt.Sprintf("%s-%s", l.LowerBound, l.UpperBound)
 }
 
+// MarshalText implements encoding.TextMarshaler, using the same format
+// as ParseLatency.
+func (l Latency) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using the same format
+// as ParseLatency. An empty text produces a zero latency.
+func (l *Latency) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*l = Latency{}
+		return nil
+	}
+
+	latency, err := ParseValidLatency(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = latency
+
+	return nil
+}
+
 // HasBounds returns true if the latency has upper and lower bounds
 // and false otherwise.
 func (l Latency) HasBounds() bool {
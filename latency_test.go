@@ -74,3 +74,50 @@ func TestParseLatency(t *testing.T) {
 		})
 	}
 }
+
+func TestLatency_Wait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		latency Latency
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "waiting without upper bound should wait for the lower bound",
+			latency: Latency{LowerBound: 10 * time.Millisecond},
+			wantMin: 10 * time.Millisecond,
+			wantMax: 10 * time.Millisecond,
+		},
+		{
+			name:    "waiting with bounds should wait between them",
+			latency: Latency{LowerBound: 10 * time.Millisecond, UpperBound: 20 * time.Millisecond},
+			wantMin: 10 * time.Millisecond,
+			wantMax: 20 * time.Millisecond,
+		},
+		{
+			name:    "waiting with equal bounds should wait for the lower bound",
+			latency: Latency{LowerBound: 10 * time.Millisecond, UpperBound: 10 * time.Millisecond},
+			wantMin: 10 * time.Millisecond,
+			wantMax: 10 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.latency.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			got := tt.latency.Wait()
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("Wait() = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
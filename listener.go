@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedNetwork is returned when a listen address uses an unsupported network.
+var ErrUnsupportedNetwork = errors.New("unsupported network, expected tcp, tcp4, tcp6 or unix")
+
+// ListenerOptions describes one of the addresses lhotse listens on.
+//
+// Listeners are expressed as URLs, such as "tcp://:8080",
+// "tcp6://[::1]:8080" or "unix:///tmp/lhotse.sock", whose query parameters
+// hold the listener's TLS certificate, PROXY protocol setting and behaviour
+// profile, such as "tcp://:8443?cert=cert.pem&key=key.pem&latency=100ms". A
+// bare address, such as ":8080", is understood as a TCP one.
+type ListenerOptions struct {
+	// Network is the network of the address: tcp, tcp4, tcp6 or unix.
+	Network string
+
+	// Addr is the address to listen on, either a host and port, or the
+	// path of a Unix domain socket.
+	Addr string
+
+	// CertFile and KeyFile are the paths of the PEM encoded certificate and
+	// private key the listener serves TLS with. TLS is disabled unless both
+	// are set.
+	CertFile string
+	KeyFile  string
+
+	// ProxyProtocol expects every connection to start with a PROXY protocol
+	// header, in either version 1 or 2, and uses the client address it
+	// holds as the connection's remote address.
+	ProxyProtocol bool
+
+	// Profile holds the behaviour applied to the requests served by the listener.
+	Profile ListenerProfile
+}
+
+// ListenerProfile describes the behaviour applied to the requests served by
+// a listener, on top of the runtime settings. Zero fields leave the runtime
+// settings untouched.
+type ListenerProfile struct {
+	// Latency is waited for before handling every request.
+	Latency Latency
+
+	// ErrorRate is the probability, between 0 and 1, for a request to be
+	// answered with an error rather than handled.
+	ErrorRate float64
+
+	// ErrorStatus is the status of the injected errors.
+	ErrorStatus int
+
+	// Bandwidth caps the transfer rate of the responses' bodies.
+	Bandwidth Bandwidth
+}
+
+// ParseListenerOptions parses a listener URL and returns a ListenerOptions struct.
+func ParseListenerOptions(text string) (ListenerOptions, error) {
+	if !strings.Contains(text, "://") {
+		options := ListenerOptions{Network: "tcp", Addr: text}
+		return options, options.Validate()
+	}
+
+	u, err := url.Parse(text)
+	if err != nil {
+		return ListenerOptions{}, fmt.Errorf("failed parsing listener: %w", err)
+	}
+
+	options := ListenerOptions{Network: u.Scheme, Addr: u.Host}
+	if options.Network == "unix" {
+		options.Addr = u.Host + u.Path
+	}
+
+	query := u.Query()
+	options.CertFile = query.Get("cert")
+	options.KeyFile = query.Get("key")
+
+	if value := query.Get("proxy_protocol"); value != "" {
+		if options.ProxyProtocol, err = strconv.ParseBool(value); err != nil {
+			return options, fmt.Errorf("failed parsing proxy_protocol parameter: %w", err)
+		}
+	}
+
+	if options.Profile, err = parseListenerProfile(query); err != nil {
+		return options, err
+	}
+
+	return options, options.Validate()
+}
+
+// parseListenerProfile parses the behaviour profile held by the "latency",
+// "error_rate", "error_status" and "bandwidth" query parameters.
+func parseListenerProfile(query url.Values) (profile ListenerProfile, err error) {
+	if value := query.Get("latency"); value != "" {
+		if profile.Latency, err = ParseValidLatency(value); err != nil {
+			return profile, fmt.Errorf("invalid latency: %w", err)
+		}
+	}
+
+	if value := query.Get("error_rate"); value != "" {
+		if profile.ErrorRate, err = strconv.ParseFloat(value, 64); err != nil {
+			return profile, fmt.Errorf("failed parsing error_rate parameter: %w", err)
+		}
+	}
+
+	if value := query.Get("error_status"); value != "" {
+		if profile.ErrorStatus, err = strconv.Atoi(value); err != nil {
+			return profile, fmt.Errorf("failed parsing error_status parameter: %w", err)
+		}
+	}
+
+	if value := query.Get("bandwidth"); value != "" {
+		if profile.Bandwidth, err = ParseBandwidth(value); err != nil {
+			return profile, fmt.Errorf("invalid bandwidth: %w", err)
+		}
+	}
+
+	return profile, nil
+}
+
+// Validate checks if the ListenerOptions struct satisfies the defined constraints.
+func (o ListenerOptions) Validate() error {
+	switch o.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedNetwork, o.Network)
+	}
+
+	if o.Addr == "" {
+		return errors.New("address cannot be empty")
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return errors.New("certificate and key must be set together")
+	}
+
+	if o.Profile.ErrorRate < 0 || o.Profile.ErrorRate > 1 {
+		return errors.New("error rate must be between 0 and 1")
+	}
+
+	if o.Profile.ErrorStatus != 0 && (o.Profile.ErrorStatus < 100 || o.Profile.ErrorStatus > 599) {
+		return errors.New("error status must be between 100 and 599")
+	}
+
+	return nil
+}
+
+// TLS returns true if the listener serves TLS.
+func (o ListenerOptions) TLS() bool {
+	return o.CertFile != ""
+}
+
+// String returns the string representation of the listener, as a URL.
+func (o ListenerOptions) String() string {
+	u := url.URL{Scheme: o.Network, Host: o.Addr}
+	if o.Network == "unix" {
+		u.Host, u.Path = "", o.Addr
+	}
+
+	return u.String()
+}
+
+// apply returns the base settings overridden by the profile.
+func (p ListenerProfile) apply(base Settings) Settings {
+	settings := base
+	if p.Latency.LowerBound > 0 {
+		settings.Latency = p.Latency
+	}
+
+	if p.ErrorRate > 0 {
+		settings.ErrorRate = p.ErrorRate
+	}
+
+	if p.ErrorStatus != 0 {
+		settings.ErrorStatus = p.ErrorStatus
+	}
+
+	if p.Bandwidth > 0 {
+		settings.Bandwidth = p.Bandwidth
+	}
+
+	return settings
+}
+
+// Listeners holds the addresses lhotse listens on.
+//
+// It implements flag.Value, each use of the flag adding a listener.
+type Listeners []ListenerOptions
+
+// String returns the string representation of the listeners.
+func (l *Listeners) String() string {
+	if l == nil {
+		return ""
+	}
+
+	urls := make([]string, 0, len(*l))
+	for _, options := range *l {
+		urls = append(urls, options.String())
+	}
+
+	return strings.Join(urls, ",")
+}
+
+// Set parses the listener URL, and adds it to the listeners.
+func (l *Listeners) Set(value string) error {
+	options, err := ParseListenerOptions(value)
+	if err != nil {
+		return fmt.Errorf("invalid listener %q: %w", value, err)
+	}
+
+	*l = append(*l, options)
+
+	return nil
+}
+
+// listenerProfileKey is the context key under which the listener's profile is stored.
+type listenerProfileKey struct{}
+
+// listenerProfile returns the profile of the listener which accepted the
+// connection the context belongs to, and false if there is none.
+func listenerProfile(ctx context.Context) (ListenerProfile, bool) {
+	profile, ok := ctx.Value(listenerProfileKey{}).(ListenerProfile)
+	return profile, ok
+}
+
+// Listener serves an http.Handler on one of the addresses lhotse listens on.
+type Listener struct {
+	options  ListenerOptions
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewListener binds the listener's address, and returns a Listener serving
+// the template's handler on it.
+//
+// The server serving the listener shares the template's connection context
+// and idle timeout, and attaches the listener's profile to the context of
+// the requests it serves.
+func NewListener(options ListenerOptions, template *http.Server) (*Listener, error) {
+	server := &http.Server{ //nolint:gosec
+		Handler:     template.Handler,
+		IdleTimeout: template.IdleTimeout,
+		ErrorLog:    template.ErrorLog,
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			if template.ConnContext != nil {
+				ctx = template.ConnContext(ctx, conn)
+			}
+
+			return context.WithValue(ctx, listenerProfileKey{}, options.Profile)
+		},
+	}
+
+	if options.TLS() {
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading TLS certificate: %w", err)
+		}
+
+		server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	listener, err := net.Listen(options.Network, options.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.ProxyProtocol {
+		listener = proxyProtocolListener{Listener: listener}
+	}
+
+	return &Listener{options: options, listener: listener, server: server}, nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Serve serves requests until the listener is shut down.
+func (l *Listener) Serve() error {
+	var err error
+	if l.options.TLS() {
+		err = l.server.ServeTLS(l.listener, "", "")
+	} else {
+		err = l.server.Serve(l.listener)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown gracefully shuts the listener down, waiting for the requests in
+// flight to be served. It closes the listener even if it was never served.
+func (l *Listener) Shutdown(ctx context.Context) error {
+	err := l.server.Shutdown(ctx)
+	_ = l.listener.Close()
+
+	return err
+}
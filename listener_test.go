@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListenerOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		text    string
+		want    ListenerOptions
+		wantErr bool
+	}{
+		{
+			name: "bare address should listen on TCP",
+			text: ":8080",
+			want: ListenerOptions{Network: "tcp", Addr: ":8080"},
+		},
+		{
+			name: "IPv6 address should be parsed",
+			text: "tcp6://[::1]:8080",
+			want: ListenerOptions{Network: "tcp6", Addr: "[::1]:8080"},
+		},
+		{
+			name: "unix socket path should be parsed",
+			text: "unix:///tmp/lhotse.sock",
+			want: ListenerOptions{Network: "unix", Addr: "/tmp/lhotse.sock"},
+		},
+		{
+			name: "TLS, PROXY protocol and profile parameters should be parsed",
+			text: "tcp://:8443?cert=cert.pem&key=key.pem&proxy_protocol=true&latency=10ms-20ms&error_rate=0.5&error_status=503&bandwidth=1kb",
+			want: ListenerOptions{
+				Network:       "tcp",
+				Addr:          ":8443",
+				CertFile:      "cert.pem",
+				KeyFile:       "key.pem",
+				ProxyProtocol: true,
+				Profile: ListenerProfile{
+					Latency:     Latency{LowerBound: 10 * time.Millisecond, UpperBound: 20 * time.Millisecond},
+					ErrorRate:   0.5,
+					ErrorStatus: http.StatusServiceUnavailable,
+					Bandwidth:   1024,
+				},
+			},
+		},
+		{
+			name:    "unsupported network should fail",
+			text:    "udp://:8080",
+			wantErr: true,
+		},
+		{
+			name:    "missing address should fail",
+			text:    "unix://",
+			wantErr: true,
+		},
+		{
+			name:    "certificate without key should fail",
+			text:    "tcp://:8443?cert=cert.pem",
+			wantErr: true,
+		},
+		{
+			name:    "invalid error rate should fail",
+			text:    "tcp://:8080?error_rate=2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseListenerOptions(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListenerOptions_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "tcp6://[::1]:8080", ListenerOptions{Network: "tcp6", Addr: "[::1]:8080"}.String())
+	assert.Equal(t, "unix:///tmp/lhotse.sock", ListenerOptions{Network: "unix", Addr: "/tmp/lhotse.sock"}.String())
+}
+
+// newListenerTestTemplate returns a server template serving /ok with the
+// runtime settings applied.
+func newListenerTestTemplate() *http.Server {
+	e := echo.New()
+	e.Use(SettingsMiddleware(NewRuntimeSettings(DefaultSettings())))
+	e.GET("/ok", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	return e.Server
+}
+
+// serveListener serves the listener until the test is over.
+func serveListener(t *testing.T, listener *Listener) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, listener.Serve())
+	}()
+
+	t.Cleanup(func() {
+		assert.NoError(t, listener.Shutdown(context.Background()))
+		<-done
+	})
+}
+
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	template := newListenerTestTemplate()
+
+	tcp, err := NewListener(ListenerOptions{Network: "tcp", Addr: "127.0.0.1:0"}, template)
+	require.NoError(t, err)
+	serveListener(t, tcp)
+
+	socket := filepath.Join(t.TempDir(), "lhotse.sock")
+	unix, err := NewListener(ListenerOptions{
+		Network: "unix",
+		Addr:    socket,
+		Profile: ListenerProfile{ErrorRate: 1, ErrorStatus: http.StatusBadGateway},
+	}, template)
+	require.NoError(t, err)
+	serveListener(t, unix)
+
+	res, err := http.Get("http://" + tcp.Addr().String() + "/ok") //nolint:noctx
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	res, err = client.Get("http://lhotse/ok") //nolint:noctx
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode, "unix listener's profile should apply")
+}
+
+func TestListener_TLS(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeTestCertificate(t)
+	listener, err := NewListener(ListenerOptions{Network: "tcp", Addr: "127.0.0.1:0", CertFile: certFile, KeyFile: keyFile}, newListenerTestTemplate())
+	require.NoError(t, err)
+	serveListener(t, listener)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+	}}
+	res, err := client.Get("https://" + listener.Addr().String() + "/ok") //nolint:noctx
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NotNil(t, res.TLS)
+
+	_, err = NewListener(ListenerOptions{Network: "tcp", Addr: "127.0.0.1:0", CertFile: keyFile, KeyFile: certFile}, newListenerTestTemplate())
+	assert.ErrorContains(t, err, "failed loading TLS certificate")
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to the
+// test's temporary directory, and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	encodedKey, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: encodedKey}), 0o600))
+
+	return certFile, keyFile
+}
@@ -2,9 +2,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -16,34 +24,451 @@ func main() {
 	// Create a logger instance
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
+	// Parse the configuration from the command line
+	config, err := ParseConfig(os.Args[1:])
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			slog.Error("Failed to parse configuration", "error_message", err.Error())
+		}
+		return
+	}
+
+	// Create the server, and the admin server if the admin API listens on its own address
+	srv, err := newServers(config, logger)
+	if err != nil {
+		slog.Error("Failed to create server", "error_message", err.Error())
+		return
+	}
+
+	// Bind every address the server, and the proxies, listen on
+	if err = srv.bind(config); err != nil {
+		slog.Error("Failed to listen", "error_message", err.Error())
+		return
+	}
+
+	// Setup signal handling for graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+
+		<-ctx.Done()
+		// Initiate graceful shutdown
+		srv.shutdown(context.Background())
+	}()
+
+	// Start the servers, until they are shut down, or every listener failed
+	if err = srv.serve(config, logger); err != nil {
+		slog.Error("Failed to serve", "error_message", err.Error())
+		stop()
+	}
+
+	// Wait for in-flight requests, and write the captured ones down
+	<-shutdownDone
+	if config.HARFile != "" {
+		if err = writeHAR(config.HARFile, NewHAR(srv.capture.List(CaptureFilter{}))); err != nil {
+			slog.Error("Failed to write HAR file", "error_message", err.Error())
+			return
+		}
+	}
+}
+
+// servers holds the Echo instances lhotse runs, and the state they share.
+type servers struct {
+	// main serves lhotse's routes.
+	main *echo.Echo
+
+	// admin serves the admin API. Unless the admin API listens on its own
+	// address, it is the same instance as main.
+	admin *echo.Echo
+
+	// capture holds the requests captured by main.
+	capture *RequestCapture
+
+	// tcpProxy is the TCP proxy, if the TCP proxy mode is enabled.
+	tcpProxy *TCPProxy
+
+	// forwardProxy is the forward proxy, if the forward proxy mode is enabled.
+	forwardProxy *ForwardProxy
+
+	// listeners are the listeners main serves lhotse's routes on.
+	listeners []*Listener
+
+	// tcpListener is the listener the TCP proxy accepts connections on.
+	tcpListener net.Listener
+
+	// forwardListener is the listener the forward proxy accepts connections on.
+	forwardListener net.Listener
+}
+
+// bind binds the addresses main, and the proxies if enabled, listen on.
+func (s *servers) bind(config Config) error {
+	listeners, err := listen(config, s.main)
+	if err != nil {
+		return err
+	}
+	s.listeners = listeners
+
+	if s.tcpProxy != nil {
+		if s.tcpListener, err = net.Listen("tcp", config.TCPProxy.Addr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", config.TCPProxy.Addr, err)
+		}
+	}
+
+	if s.forwardProxy != nil {
+		if s.forwardListener, err = net.Listen("tcp", config.ForwardProxy.Addr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", config.ForwardProxy.Addr, err)
+		}
+	}
+
+	return nil
+}
+
+// serve starts the admin server, if it listens on its own address, and the
+// proxies, if enabled, and serves lhotse's routes on every listener until
+// they are shut down. It returns an error if every listener failed.
+func (s *servers) serve(config Config, logger *slog.Logger) error {
+	if s.admin != s.main {
+		go func() {
+			if err := s.admin.Start(config.Admin.Addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Failed to start admin server", "error_message", err.Error())
+			}
+		}()
+	}
+
+	if s.tcpProxy != nil {
+		go func() {
+			logger.Info("TCP proxy listening", "addr", config.TCPProxy.Addr, "upstream", config.TCPProxy.Upstream)
+			if err := s.tcpProxy.Serve(s.tcpListener); err != nil {
+				slog.Error("Failed to start TCP proxy", "error_message", err.Error())
+			}
+		}()
+	}
+
+	if s.forwardProxy != nil {
+		go func() {
+			logger.Info("Forward proxy listening", "addr", config.ForwardProxy.Addr, "auth", config.ForwardProxy.Auth != "")
+			if err := s.forwardProxy.Serve(s.forwardListener); err != nil {
+				slog.Error("Failed to start forward proxy", "error_message", err.Error())
+			}
+		}()
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int64
+	)
+	for _, listener := range s.listeners {
+		wg.Add(1)
+		go func(listener *Listener) {
+			defer wg.Done()
+
+			logger.Info("Server listening", "listener", listener.options.String(), "tls", listener.options.TLS())
+			if err := listener.Serve(); err != nil {
+				failed.Add(1)
+				slog.Error("Failed to start server", "listener", listener.options.String(), "error_message", err.Error())
+			}
+		}(listener)
+	}
+	wg.Wait()
+
+	if len(s.listeners) > 0 && failed.Load() == int64(len(s.listeners)) {
+		return errors.New("every listener failed")
+	}
+
+	return nil
+}
+
+// shutdown gracefully shuts every listener down, along with the proxies and
+// the admin server.
+func (s *servers) shutdown(ctx context.Context) {
+	for _, listener := range s.listeners {
+		if err := listener.Shutdown(ctx); err != nil {
+			slog.Error("Failed to shutdown server", "listener", listener.options.String(), "error_message", err.Error())
+		}
+	}
+
+	if s.tcpProxy != nil {
+		if err := s.tcpProxy.Close(); err != nil {
+			slog.Error("Failed to shutdown TCP proxy", "error_message", err.Error())
+		}
+	}
+
+	if s.forwardProxy != nil {
+		if err := s.forwardProxy.Shutdown(ctx); err != nil {
+			slog.Error("Failed to shutdown forward proxy", "error_message", err.Error())
+		}
+	}
+
+	if s.admin != s.main {
+		if err := s.admin.Shutdown(ctx); err != nil {
+			slog.Error("Failed to shutdown admin server", "error_message", err.Error())
+		}
+	}
+}
+
+// newServers creates the Echo instance serving lhotse's routes, and the one
+// serving the admin API.
+func newServers(config Config, logger *slog.Logger) (*servers, error) {
 	// Create a new Echo instance
 	e := echo.New()
 
 	// Configure the Echo instance
 	e.HideBanner = true
+	e.Server.ConnContext = NewConnContext()
+	e.Server.IdleTimeout = config.Connection.IdleTimeout
 
 	// Register middleware
 	e.Use(slogecho.New(logger))
 	e.Use(middleware.Recover())
+	e.Use(ConnectionMiddleware(config.Connection, handedOver(config)))
 
 	// Register route handlers
-	RegisterHandlers(e, &ServerImpl{})
+	server := NewServerImpl(config)
+	RegisterHandlers(e, server)
+	if config.RoutesFile != "" {
+		routes, err := loadRoutes(config.RoutesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load routes: %w", err)
+		}
+		RegisterRoutes(e, routes)
+	}
+	if config.Mock.File != "" {
+		mock, err := loadMock(config.Mock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load OpenAPI mock: %w", err)
+		}
+		if err = mock.Register(e); err != nil {
+			return nil, fmt.Errorf("failed to register OpenAPI mock: %w", err)
+		}
+	}
 
-	// Setup signal handling for graceful shutdown
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, os.Interrupt)
-	go func() {
-		<-signalCh
-		// Initiate graceful shutdown
-		if shutdownErr := e.Shutdown(context.Background()); shutdownErr != nil {
-			slog.Error("Failed to shutdown server", "error_message", shutdownErr.Error())
-			return
+	// Serve the admin API, either on its own address or under its prefix
+	settings := NewRuntimeSettings(DefaultSettings())
+	if config.ScheduleFile != "" {
+		schedule, err := loadSchedule(config.ScheduleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load schedule: %w", err)
 		}
-	}()
+		if err = settings.SetSchedule(schedule); err != nil {
+			return nil, fmt.Errorf("failed to apply schedule: %w", err)
+		}
+	}
+	capture := NewRequestCapture(config.Capture)
+	stubs := NewStubs()
+	admin, adminPrefixes := e, []string{config.Admin.Prefix}
+	if config.Admin.Addr != "" {
+		admin, adminPrefixes = newAdminServer(logger), nil
+	}
+	e.Use(CaptureMiddleware(capture, adminPrefixes...))
+	if config.Workers.Enabled() {
+		e.Use(WorkerPoolMiddleware(NewWorkerPool(config.Workers, server.metrics), adminPrefixes...))
+	}
+	e.Use(SettingsMiddleware(settings, adminPrefixes...))
+	if config.Queueing.Enabled() {
+		e.Use(QueueingMiddleware(NewQueueingModel(config.Queueing), adminPrefixes...))
+	}
+	e.Use(StubsMiddleware(stubs, adminPrefixes...))
+	if config.Replay.File != "" {
+		replayer, err := loadReplayer(config.Replay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load replay file: %w", err)
+		}
+		e.Use(ReplayMiddleware(replayer, adminPrefixes...))
+	}
+	if config.Proxy.Enabled() {
+		proxy, err := loadProxy(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load proxy: %w", err)
+		}
+		e.Use(ProxyMiddleware(proxy, adminPrefixes...))
+	}
+	adminAPI := NewAdmin(settings, capture, stubs)
+	tcpProxy, forwardProxy := newProxies(config, adminAPI)
+	RegisterAdminHandlers(admin, adminAPI, config.Admin.Prefix)
 
-	// Start the server
-	if err := e.Start(":3434"); err != nil {
-		slog.Error("Failed to start server", "error_message", err.Error())
-		return
+	return &servers{main: e, admin: admin, capture: capture, tcpProxy: tcpProxy, forwardProxy: forwardProxy}, nil
+}
+
+// handedOver returns a function reporting whether requests are handed over
+// to the reverse proxy's upstream, or to a mocked operation, rather than
+// served by lhotse's own routes.
+func handedOver(config Config) func(echo.Context) bool {
+	return func(ctx echo.Context) bool {
+		if config.Proxy.Enabled() && (config.Admin.Addr != "" || !hasAnyPathPrefix(ctx.Request().URL.Path, []string{config.Admin.Prefix})) {
+			return true
+		}
+
+		return isMockRoute(ctx)
+	}
+}
+
+// newProxies creates the TCP proxy and the forward proxy, if their modes are
+// enabled, and makes the admin API control them.
+func newProxies(config Config, adminAPI *Admin) (tcpProxy *TCPProxy, forwardProxy *ForwardProxy) {
+	if config.TCPProxy.Enabled() {
+		tcpProxy = NewTCPProxy(config.TCPProxy)
+		adminAPI.WithTCPProxy(tcpProxy)
+	}
+
+	if config.ForwardProxy.Enabled() {
+		forwardProxy = NewForwardProxy(config.ForwardProxy)
+		adminAPI.WithForwardProxy(forwardProxy)
+	}
+
+	return tcpProxy, forwardProxy
+}
+
+// listen binds the addresses the server listens on: the ones of the listen
+// options, or its address if there are none.
+func listen(config Config, e *echo.Echo) ([]*Listener, error) {
+	options := config.Listeners
+	if len(options) == 0 {
+		options = Listeners{{Network: "tcp", Addr: config.Addr}}
+	}
+
+	listeners := make([]*Listener, 0, len(options))
+	for _, option := range options {
+		listener, err := NewListener(option, e.Server)
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Shutdown(context.Background())
+			}
+
+			return nil, fmt.Errorf("failed to listen on %s: %w", option, err)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+// newAdminServer creates the Echo instance serving the admin API on its own address.
+func newAdminServer(logger *slog.Logger) *echo.Echo {
+	admin := echo.New()
+	admin.HideBanner = true
+	admin.HidePort = true
+	admin.Use(slogecho.New(logger))
+	admin.Use(middleware.Recover())
+
+	return admin
+}
+
+// loadRoutes parses and validates the routes defined by the routes file.
+//
+//nolint:forbidigo
+func loadRoutes(path string) ([]RouteDefinition, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	routes, err := ParseRoutes(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return routes, nil
+}
+
+// loadSchedule parses and validates the schedule defined by the schedule file.
+//
+//nolint:forbidigo
+func loadSchedule(path string) (Schedule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Schedule{}, err
+	}
+	defer file.Close()
+
+	schedule, err := ParseSchedule(file)
+	if err != nil {
+		return schedule, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return schedule, nil
+}
+
+// writeHAR writes the HTTP Archive to the file, replacing it if it exists.
+//
+//nolint:forbidigo
+func writeHAR(path string, har HAR) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(har); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// loadReplayer creates a Replayer replaying the HAR file of the replay options.
+//
+//nolint:forbidigo
+func loadReplayer(options ReplayOptions) (*Replayer, error) {
+	file, err := os.Open(options.File)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
+
+	har, err := ParseHAR(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", options.File, err)
+	}
+
+	replayer, err := NewReplayer(har, options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", options.File, err)
+	}
+
+	return replayer, nil
+}
+
+// loadProxy creates a Proxy forwarding requests to the upstream of the proxy
+// options, and injecting the faults defined by their rules file, if any.
+//
+//nolint:forbidigo
+func loadProxy(options ProxyOptions) (*Proxy, error) {
+	var rules []ProxyRule
+	if options.RulesFile != "" {
+		file, err := os.Open(options.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if rules, err = ParseProxyRules(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", options.RulesFile, err)
+		}
+	}
+
+	return NewProxy(options, rules)
+}
+
+// loadMock creates a Mock of the OpenAPI document of the mock options.
+//
+//nolint:forbidigo
+func loadMock(options MockOptions) (*Mock, error) {
+	data, err := os.ReadFile(options.File)
+	if err != nil {
+		return nil, err
+	}
+
+	mock, err := LoadMock(data, options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", options.File, err)
+	}
+
+	return mock, nil
 }
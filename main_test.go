@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServers_Serve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		failing int
+		serving int
+		wantErr bool
+	}{
+		{"every listener failing should fail", 2, 0, true},
+		{"some listeners failing should not fail", 1, 1, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			srv := &servers{main: e, admin: e}
+
+			for i := 0; i < tt.failing+tt.serving; i++ {
+				listener, err := NewListener(ListenerOptions{Network: "tcp", Addr: "127.0.0.1:0"}, e.Server)
+				require.NoError(t, err)
+				srv.listeners = append(srv.listeners, listener)
+
+				if i < tt.failing {
+					// Closing the bound listener makes serving it fail
+					require.NoError(t, listener.listener.Close())
+				}
+			}
+
+			// Shut the serving listeners down once the failing ones are done with
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				srv.shutdown(context.Background())
+			}()
+
+			err := srv.serve(Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RequestMatcher describes the requests satisfying a set of conditions.
+//
+// Empty fields match any request.
+type RequestMatcher struct {
+	// Method matches the request method, case insensitively.
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+
+	// Path is a regular expression the whole request path must match.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Headers match the request headers, by name. An empty value matches
+	// requests holding the header, whatever its value.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// Query matches the request query parameters, by name. An empty value
+	// matches requests holding the parameter, whatever its value.
+	Query map[string]string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// BodyContains matches requests whose body contains it.
+	BodyContains string `json:"body_contains,omitempty" yaml:"body_contains,omitempty"`
+
+	path *regexp.Regexp
+}
+
+// Compile validates the matcher, and prepares it for matching. It must be
+// called before Match.
+func (m *RequestMatcher) Compile() error {
+	if m.Path == "" {
+		m.path = nil
+		return nil
+	}
+
+	path, err := regexp.Compile("^(?:" + m.Path + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid path pattern: %w", err)
+	}
+
+	m.path = path
+
+	return nil
+}
+
+// MatchedBodyLimit is the number of bytes of a request body matchers match:
+// BodyContains only looks for its content in the beginning of longer bodies.
+const MatchedBodyLimit = 64 * 1024
+
+// Match returns true if the request satisfies the matcher.
+func (m *RequestMatcher) Match(req CapturedRequest) bool {
+	return m.matchHead(req) && strings.Contains(req.Body, m.BodyContains)
+}
+
+// NeedsBody returns true if matching the request depends on its body: the
+// matcher matches bodies, and the request satisfies its other conditions.
+func (m *RequestMatcher) NeedsBody(req CapturedRequest) bool {
+	return m.BodyContains != "" && m.matchHead(req)
+}
+
+// matchHead returns true if the request satisfies the conditions of the
+// matcher, but the one on its body.
+func (m *RequestMatcher) matchHead(req CapturedRequest) bool {
+	if m.Method != "" && !strings.EqualFold(m.Method, req.Method) {
+		return false
+	}
+
+	if m.path != nil && !m.path.MatchString(req.Path) {
+		return false
+	}
+
+	for name, value := range m.Headers {
+		values, ok := req.Headers[http.CanonicalHeaderKey(name)]
+		if !ok || (value != "" && !slices.Contains(values, value)) {
+			return false
+		}
+	}
+
+	if len(m.Query) > 0 {
+		query, err := url.ParseQuery(req.Query)
+		if err != nil {
+			return false
+		}
+
+		for name, value := range m.Query {
+			values, ok := query[name]
+			if !ok || (value != "" && !slices.Contains(values, value)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// newMatchedRequest returns the request in the form matchers match. Its body
+// is only read if needsBody returns true for the request without it, up to
+// MatchedBodyLimit bytes, and put back in front of the rest of the body for
+// the handler to read.
+func newMatchedRequest(req *http.Request, needsBody func(CapturedRequest) bool) (CapturedRequest, error) {
+	matched := CapturedRequest{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   req.URL.RawQuery,
+		Headers: req.Header,
+	}
+
+	if req.Body == nil || req.Body == http.NoBody || !needsBody(matched) {
+		return matched, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, MatchedBodyLimit))
+	if err != nil {
+		return CapturedRequest{}, err
+	}
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	matched.Body = string(body)
+
+	return matched, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	req := CapturedRequest{
+		Method:  http.MethodPost,
+		Path:    "/upload/avatar",
+		Query:   "tenant=a&debug",
+		Headers: http.Header{"X-Tenant": []string{"a"}},
+		Body:    `{"name":"alice"}`,
+	}
+
+	tests := []struct {
+		name    string
+		matcher RequestMatcher
+		want    bool
+	}{
+		{
+			name:    "empty matcher should match",
+			matcher: RequestMatcher{},
+			want:    true,
+		},
+		{
+			name:    "method should match case insensitively",
+			matcher: RequestMatcher{Method: "post"},
+			want:    true,
+		},
+		{
+			name:    "path pattern should match the whole path",
+			matcher: RequestMatcher{Path: "/upload/.*"},
+			want:    true,
+		},
+		{
+			name:    "partial path pattern should not match",
+			matcher: RequestMatcher{Path: "/upload"},
+			want:    false,
+		},
+		{
+			name:    "header value should match",
+			matcher: RequestMatcher{Headers: map[string]string{"x-tenant": "a"}},
+			want:    true,
+		},
+		{
+			name:    "different header value should not match",
+			matcher: RequestMatcher{Headers: map[string]string{"X-Tenant": "b"}},
+			want:    false,
+		},
+		{
+			name:    "query parameter presence should match",
+			matcher: RequestMatcher{Query: map[string]string{"debug": ""}},
+			want:    true,
+		},
+		{
+			name:    "missing query parameter should not match",
+			matcher: RequestMatcher{Query: map[string]string{"page": ""}},
+			want:    false,
+		},
+		{
+			name:    "body content should match",
+			matcher: RequestMatcher{BodyContains: "alice"},
+			want:    true,
+		},
+		{
+			name:    "different body content should not match",
+			matcher: RequestMatcher{BodyContains: "bob"},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, tt.matcher.Compile())
+			assert.Equal(t, tt.want, tt.matcher.Match(req))
+		})
+	}
+}
+
+func TestRequestMatcher_Compile(t *testing.T) {
+	t.Parallel()
+
+	matcher := RequestMatcher{Path: "/upload/("}
+	assert.Error(t, matcher.Compile())
+}
+
+func TestRequestMatcher_NeedsBody(t *testing.T) {
+	t.Parallel()
+
+	req := CapturedRequest{Method: http.MethodPost, Path: "/upload/avatar"}
+
+	for _, tt := range []struct {
+		name    string
+		matcher RequestMatcher
+		want    bool
+	}{
+		{"matcher without body condition should not need the body", RequestMatcher{Method: http.MethodPost}, false},
+		{"matcher with body condition should need the body", RequestMatcher{BodyContains: "alice"}, true},
+		{"matcher the request does not satisfy otherwise should not need the body", RequestMatcher{Method: http.MethodGet, BodyContains: "alice"}, false},
+	} {
+		require.NoError(t, tt.matcher.Compile())
+		assert.Equal(t, tt.want, tt.matcher.NeedsBody(req), tt.name)
+	}
+}
+
+func TestNewMatchedRequest(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("a", MatchedBodyLimit+1024)
+
+	tests := []struct {
+		name      string
+		needsBody bool
+		wantBody  string
+	}{
+		{
+			name:      "body should only be read up to the limit",
+			needsBody: true,
+			wantBody:  body[:MatchedBodyLimit],
+		},
+		{
+			name:      "body should not be read when not needed",
+			needsBody: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload?tenant=a", strings.NewReader(body))
+
+			matched, err := newMatchedRequest(req, func(got CapturedRequest) bool {
+				assert.Equal(t, "/upload", got.Path)
+				assert.Equal(t, "tenant=a", got.Query)
+
+				return tt.needsBody
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, matched.Body)
+
+			rest, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(rest), "handler should read the whole body")
+		})
+	}
+}
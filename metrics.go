@@ -0,0 +1,57 @@
+package main
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics holds the counters and gauges lhotse exposes on its /metrics endpoint.
+//
+// Metric names are dot-separated paths, such as "ratelimit.default.limited".
+type Metrics struct {
+	vars *expvar.Map
+}
+
+// NewMetrics creates a new, empty, Metrics instance.
+//
+// The metrics are not published to the global expvar registry, so that
+// multiple instances can coexist.
+func NewMetrics() *Metrics {
+	return &Metrics{vars: new(expvar.Map).Init()}
+}
+
+// Add adds delta to the named counter, creating it if needed.
+func (m *Metrics) Add(name string, delta int64) {
+	m.vars.Add(name, delta)
+}
+
+// Set sets the named gauge to value, creating it if needed.
+func (m *Metrics) Set(name string, value int64) {
+	gauge := new(expvar.Int)
+	gauge.Set(value)
+	m.vars.Set(name, gauge)
+}
+
+// Get returns the value of the named metric, and false if it does not exist.
+func (m *Metrics) Get(name string) (int64, bool) {
+	value, ok := m.vars.Get(name).(*expvar.Int)
+	if !ok {
+		return 0, false
+	}
+
+	return value.Value(), true
+}
+
+// String returns the JSON representation of the metrics.
+func (m *Metrics) String() string {
+	return m.vars.String()
+}
+
+// GetMetrics is a handler returning the server's metrics.
+//
+// The response is a JSON object holding the metrics' values indexed by their names.
+func (s *ServerImpl) GetMetrics(ctx echo.Context) error {
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationJSON, []byte(s.metrics.String()))
+}
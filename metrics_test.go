@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMetrics(t *testing.T) {
+	t.Parallel()
+
+	server := NewServerImpl(Config{})
+	server.metrics.Add("requests", 1)
+	server.metrics.Add("requests", 2)
+	server.metrics.Set("limit", 10)
+	server.metrics.Set("limit", 20)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, server.GetMetrics(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"limit":20,"requests":3}`, rec.Body.String())
+}
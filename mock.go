@@ -0,0 +1,493 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MockLatencyExtension is the operation extension overriding the mock's latency.
+	MockLatencyExtension = "x-lhotse-latency"
+
+	// MockErrorRateExtension is the operation extension overriding the mock's error rate.
+	MockErrorRateExtension = "x-lhotse-error-rate"
+
+	// MockErrorStatusExtension is the operation extension overriding the mock's error status.
+	MockErrorStatusExtension = "x-lhotse-error-status"
+)
+
+// MockRouteName is the name of the routes serving mocked operations.
+const MockRouteName = "lhotse.mock"
+
+// mockMaxDepth caps how deep schemas are followed when generating examples,
+// so that recursive schemas produce finite examples.
+const mockMaxDepth = 8
+
+// MockOptions holds the options of the OpenAPI mock.
+type MockOptions struct {
+	// File is the path of the OpenAPI document to mock. Empty disables the mock.
+	File string
+
+	// Validate enables the validation of requests against the document.
+	Validate bool
+
+	// Behaviour is the default behaviour of every operation, which can be
+	// overridden using the x-lhotse-* operation extensions.
+	Behaviour MockBehaviour
+}
+
+// DefaultMockOptions returns the default mock options.
+func DefaultMockOptions() MockOptions {
+	return MockOptions{
+		Validate:  true,
+		Behaviour: MockBehaviour{ErrorStatus: DefaultErrorStatus},
+	}
+}
+
+// MockBehaviour describes the latency and errors injected into a mocked operation.
+type MockBehaviour struct {
+	// Latency is waited for before responding.
+	Latency Latency
+
+	// ErrorRate is the probability, between 0 and 1, for a request to be
+	// answered with an error rather than a mocked response.
+	ErrorRate float64
+
+	// ErrorStatus is the status of the injected errors.
+	ErrorStatus int
+}
+
+// Validate checks if the MockBehaviour struct satisfies the defined constraints.
+func (b MockBehaviour) Validate() error {
+	if err := b.Latency.Validate(); err != nil {
+		return fmt.Errorf("invalid latency: %w", err)
+	}
+
+	if b.ErrorRate < 0 || b.ErrorRate > 1 {
+		return errors.New("error rate must be between 0 and 1")
+	}
+
+	if b.ErrorStatus < 100 || b.ErrorStatus > 599 {
+		return errors.New("error status must be between 100 and 599")
+	}
+
+	return nil
+}
+
+// withExtensions returns the behaviour overridden by the operation's x-lhotse-* extensions.
+func (b MockBehaviour) withExtensions(extensions map[string]any) (MockBehaviour, error) {
+	if raw, ok := extensions[MockLatencyExtension]; ok {
+		duration, isString := raw.(string)
+		if !isString {
+			return b, fmt.Errorf("%s must be a string", MockLatencyExtension)
+		}
+
+		latency, err := ParseValidLatency(duration)
+		if err != nil {
+			return b, fmt.Errorf("invalid %s: %w", MockLatencyExtension, err)
+		}
+		b.Latency = latency
+	}
+
+	if raw, ok := extensions[MockErrorRateExtension]; ok {
+		rate, isNumber := raw.(float64)
+		if !isNumber {
+			return b, fmt.Errorf("%s must be a number", MockErrorRateExtension)
+		}
+		b.ErrorRate = rate
+	}
+
+	if raw, ok := extensions[MockErrorStatusExtension]; ok {
+		status, isNumber := raw.(float64)
+		if !isNumber {
+			return b, fmt.Errorf("%s must be a number", MockErrorStatusExtension)
+		}
+		b.ErrorStatus = int(status)
+	}
+
+	return b, b.Validate()
+}
+
+// Mock serves the operations of an OpenAPI document with example responses.
+type Mock struct {
+	doc     *openapi3.T
+	options MockOptions
+}
+
+// LoadMock loads and validates an OpenAPI 3 document, in either YAML or JSON.
+func LoadMock(data []byte, options MockOptions) (*Mock, error) {
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading OpenAPI document: %w", err)
+	}
+
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	return &Mock{doc: doc, options: options}, nil
+}
+
+// Register registers a route for every operation of the document on the Echo instance.
+// Routes are prefixed with the base path of the operation's first server,
+// such as "/v1" for "https://api.example.com/v1".
+//
+// Routes registered after the server's own routes, with the same method and
+// path, take precedence over them.
+func (m *Mock) Register(e *echo.Echo) error {
+	paths := m.doc.Paths.Map()
+
+	keys := make([]string, 0, len(paths))
+	for path := range paths {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+
+	for _, path := range keys {
+		pathItem := paths[path]
+		for method, operation := range pathItem.Operations() {
+			behaviour, err := m.options.Behaviour.withExtensions(operation.Extensions)
+			if err != nil {
+				return fmt.Errorf("operation %s %s: %w", method, path, err)
+			}
+
+			basePath, err := mockBasePath(m.doc.Servers, pathItem.Servers, operation.Servers)
+			if err != nil {
+				return fmt.Errorf("operation %s %s: invalid server url: %w", method, path, err)
+			}
+
+			route := &routers.Route{
+				Spec:      m.doc,
+				Path:      path,
+				PathItem:  pathItem,
+				Method:    method,
+				Operation: operation,
+			}
+
+			e.Add(method, basePath+openAPIParamRegexp.ReplaceAllString(path, ":$1"), m.handler(route, behaviour)).Name = MockRouteName
+		}
+	}
+
+	return nil
+}
+
+// isMockRoute returns true if the request was routed to a mocked operation.
+func isMockRoute(ctx echo.Context) bool {
+	for _, route := range ctx.Echo().Routes() {
+		if route.Method == ctx.Request().Method && route.Path == ctx.Path() {
+			return route.Name == MockRouteName
+		}
+	}
+
+	return false
+}
+
+// mockBasePath returns the base path of an operation, given the servers of
+// its document, path and operation: the most specific ones apply. The base
+// path has no trailing slash, and is empty for servers without one.
+func mockBasePath(docServers, pathServers openapi3.Servers, operationServers *openapi3.Servers) (string, error) {
+	servers := docServers
+	if len(pathServers) > 0 {
+		servers = pathServers
+	}
+	if operationServers != nil && len(*operationServers) > 0 {
+		servers = *operationServers
+	}
+
+	basePath, err := servers.BasePath()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(basePath, "/"), nil
+}
+
+// handler returns the handler serving a mocked operation.
+func (m *Mock) handler(route *routers.Route, behaviour MockBehaviour) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if m.options.Validate {
+			pathParams := make(map[string]string, len(ctx.ParamNames()))
+			for i, name := range ctx.ParamNames() {
+				pathParams[name] = ctx.ParamValues()[i]
+			}
+
+			err := openapi3filter.ValidateRequest(ctx.Request().Context(), &openapi3filter.RequestValidationInput{
+				Request:    ctx.Request(),
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+					MultiError:         true,
+				},
+			})
+			if err != nil {
+				slog.Error(
+					"failed validating request",
+					"handler", "Mock",
+					"operation", route.Method+" "+route.Path,
+					"error_message", err.Error(),
+				)
+
+				return ctx.String(http.StatusBadRequest, err.Error())
+			}
+		}
+
+		behaviour.Latency.Wait()
+
+		//nolint:gosec
+		if behaviour.ErrorRate > 0 && rand.Float64() < behaviour.ErrorRate {
+			return ctx.String(behaviour.ErrorStatus, "injected error")
+		}
+
+		status, contentType, body, err := mockResponse(route.Operation, ctx.Request().Header.Get(echo.HeaderAccept))
+		if err != nil {
+			return err
+		}
+
+		if contentType == "" {
+			return ctx.NoContent(status)
+		}
+
+		return ctx.Blob(status, contentType, body)
+	}
+}
+
+// mockResponse returns the status, content type and body of the response
+// mocking the operation.
+//
+// The response is the operation's lowest success response, or its default
+// one. Its content type is the first one accepted by the client, preferring
+// JSON otherwise, and its body is the content's example, or one generated
+// from its schema.
+func mockResponse(operation *openapi3.Operation, accept string) (int, string, []byte, error) {
+	status, response := mockResponseStatus(operation.Responses)
+	if response == nil || len(response.Content) == 0 {
+		return status, "", nil, nil
+	}
+
+	contentTypes := make([]string, 0, len(response.Content))
+	for contentType := range response.Content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	contentType := contentTypes[0]
+	if response.Content.Get(echo.MIMEApplicationJSON) != nil {
+		contentType = echo.MIMEApplicationJSON
+	}
+	for _, candidate := range contentTypes {
+		if accept != "" && strings.Contains(accept, candidate) {
+			contentType = candidate
+			break
+		}
+	}
+
+	example := mediaTypeExample(response.Content.Get(contentType))
+	if text, ok := example.(string); ok && !strings.Contains(contentType, "json") {
+		return status, contentType, []byte(text), nil
+	}
+
+	body, err := json.Marshal(example)
+	if err != nil {
+		return 0, "", nil, fmt.Errorf("failed encoding example: %w", err)
+	}
+
+	return status, contentType, body, nil
+}
+
+// mockResponseStatus returns the lowest success status of the responses,
+// and its description. It falls back to the default response, served as a
+// 200, and to the lowest status otherwise.
+func mockResponseStatus(responses *openapi3.Responses) (int, *openapi3.Response) {
+	if responses == nil {
+		return http.StatusOK, nil
+	}
+
+	best := math.MaxInt
+	var bestResponse *openapi3.Response
+	for key, ref := range responses.Map() {
+		// Ranges, such as 2XX, are served as their lowest status
+		status, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(key), "X", "0"))
+		if err != nil {
+			continue
+		}
+
+		// Success statuses come first
+		rank := status
+		if status < 200 || status > 299 {
+			rank += 1000
+		}
+
+		if rank < best {
+			best, bestResponse = rank, ref.Value
+		}
+	}
+
+	if bestResponse != nil && best < 1000 {
+		return best, bestResponse
+	}
+
+	if def := responses.Default(); def != nil {
+		return http.StatusOK, def.Value
+	}
+
+	if bestResponse != nil {
+		return best - 1000, bestResponse
+	}
+
+	return http.StatusOK, nil
+}
+
+// mediaTypeExample returns the media type's example, its first named
+// example, or one generated from its schema.
+func mediaTypeExample(mediaType *openapi3.MediaType) any {
+	if mediaType == nil {
+		return nil
+	}
+
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+
+	names := make([]string, 0, len(mediaType.Examples))
+	for name := range mediaType.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ref := mediaType.Examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value
+		}
+	}
+
+	if mediaType.Schema == nil {
+		return nil
+	}
+
+	return generateExample(mediaType.Schema.Value, 0)
+}
+
+// generateExample generates a value satisfying the schema, using its
+// example, default or enumeration when available.
+//
+//nolint:cyclop
+func generateExample(schema *openapi3.Schema, depth int) any {
+	if schema == nil || depth > mockMaxDepth {
+		return nil
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.OneOf) > 0:
+		return generateExample(schema.OneOf[0].Value, depth+1)
+	case len(schema.AnyOf) > 0:
+		return generateExample(schema.AnyOf[0].Value, depth+1)
+	case len(schema.AllOf) > 0:
+		merged := make(map[string]any)
+		for _, ref := range schema.AllOf {
+			if object, ok := generateExample(ref.Value, depth+1).(map[string]any); ok {
+				for name, value := range object {
+					merged[name] = value
+				}
+			}
+		}
+		return merged
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeObject) || (schema.Type == nil && len(schema.Properties) > 0):
+		object := make(map[string]any, len(schema.Properties))
+		for name, ref := range schema.Properties {
+			object[name] = generateExample(ref.Value, depth+1)
+		}
+		return object
+	case schema.Type.Is(openapi3.TypeArray):
+		if schema.Items == nil {
+			return []any{}
+		}
+		return []any{generateExample(schema.Items.Value, depth+1)}
+	case schema.Type.Is(openapi3.TypeString):
+		return generateString(schema)
+	case schema.Type.Is(openapi3.TypeInteger):
+		return int64(generateNumber(schema, 1))
+	case schema.Type.Is(openapi3.TypeNumber):
+		return generateNumber(schema, 0.5)
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return true
+	default:
+		return nil
+	}
+}
+
+// generateString generates a string satisfying the schema's format and length.
+func generateString(schema *openapi3.Schema) string {
+	switch schema.Format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	case "byte":
+		return "bGhvdHNl"
+	}
+
+	value := "string"
+	if schema.MinLength > uint64(len(value)) {
+		value = strings.Repeat("s", int(schema.MinLength))
+	}
+	if schema.MaxLength != nil && *schema.MaxLength < uint64(len(value)) {
+		value = value[:*schema.MaxLength]
+	}
+
+	return value
+}
+
+// generateNumber generates a number within the schema's bounds, stepping
+// away from exclusive bounds by step.
+func generateNumber(schema *openapi3.Schema, step float64) float64 {
+	switch {
+	case schema.Min != nil && schema.ExclusiveMin:
+		return *schema.Min + step
+	case schema.Min != nil:
+		return *schema.Min
+	case schema.Max != nil && schema.ExclusiveMax:
+		return math.Min(0, *schema.Max-step)
+	case schema.Max != nil:
+		return math.Min(0, *schema.Max)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockTestDocument = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+      responses:
+        "200":
+          description: The pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      x-lhotse-latency: 20ms
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: The created pet
+          content:
+            application/json:
+              example: {"id": 42, "name": "rex"}
+        default:
+          description: An error
+  /pets/{id}:
+    get:
+      x-lhotse-error-rate: 1
+      x-lhotse-error-status: 503
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: A pet
+          content:
+            text/plain:
+              schema:
+                type: string
+                example: rex
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+        tag:
+          type: string
+          enum: [dog, cat]
+        born:
+          type: string
+          format: date
+`
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	mock, err := LoadMock([]byte(mockTestDocument), DefaultMockOptions())
+	require.NoError(t, err)
+
+	e := echo.New()
+	require.NoError(t, mock.Register(e))
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantLatency time.Duration
+	}{
+		{
+			name:       "operation should respond with a schema generated example",
+			method:     http.MethodGet,
+			target:     "/pets",
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"id":0,"name":"string","tag":"dog","born":"2024-01-01"}]`,
+		},
+		{
+			name:       "invalid query parameter should fail",
+			method:     http.MethodGet,
+			target:     "/pets?limit=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "operation should respond with its example, after its latency",
+			method:      http.MethodPost,
+			target:      "/pets",
+			body:        `{"name":"rex"}`,
+			wantStatus:  http.StatusCreated,
+			wantBody:    `{"id":42,"name":"rex"}`,
+			wantLatency: 20 * time.Millisecond,
+		},
+		{
+			name:       "invalid body should fail",
+			method:     http.MethodPost,
+			target:     "/pets",
+			body:       `{"id":1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "operation error rate should inject errors",
+			method:     http.MethodGet,
+			target:     "/pets/1",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			start := time.Now()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.GreaterOrEqual(t, time.Since(start), tt.wantLatency)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMock_ServerBasePath(t *testing.T) {
+	t.Parallel()
+
+	mock, err := LoadMock([]byte(`
+openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    get:
+      responses:
+        "204":
+          description: The users
+  /health:
+    servers:
+      - url: /
+    get:
+      responses:
+        "204":
+          description: Healthy
+  /legacy:
+    get:
+      servers:
+        - url: https://{host}/{version}
+          variables:
+            host:
+              default: api.example.com
+            version:
+              default: v0
+      responses:
+        "204":
+          description: The legacy users
+`), DefaultMockOptions())
+	require.NoError(t, err)
+
+	e := echo.New()
+	require.NoError(t, mock.Register(e))
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"operation should be served under the document's server path", "/v1/users", http.StatusNoContent},
+		{"operation should not be served without the server path", "/users", http.StatusNotFound},
+		{"path servers should override the document's", "/health", http.StatusNoContent},
+		{"operation servers should override the document's, with their variables' defaults", "/v0/legacy", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestIsMockRoute(t *testing.T) {
+	t.Parallel()
+
+	mock, err := LoadMock([]byte(mockTestDocument), DefaultMockOptions())
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.GET("/health", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+	e.GET("/pets", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+	require.NoError(t, mock.Register(e))
+
+	for _, tt := range []struct {
+		target string
+		want   bool
+	}{
+		{"/health", false},
+		{"/pets", true},
+		{"/pets/1", true},
+	} {
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, tt.target, nil), httptest.NewRecorder())
+		e.Router().Find(http.MethodGet, tt.target, ctx)
+
+		assert.Equal(t, tt.want, isMockRoute(ctx), tt.target)
+	}
+}
+
+func TestLoadMock(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadMock([]byte(`openapi: 3.0.3`), DefaultMockOptions())
+	assert.Error(t, err, "invalid document should fail")
+
+	mock, err := LoadMock([]byte(strings.Replace(mockTestDocument, "x-lhotse-latency: 20ms", "x-lhotse-latency: soon", 1)), DefaultMockOptions())
+	require.NoError(t, err)
+	assert.ErrorContains(t, mock.Register(echo.New()), "operation POST /pets: invalid x-lhotse-latency")
+}
+
+func TestMockResponse(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(mockTestDocument))
+	require.NoError(t, err)
+
+	status, contentType, body, err := mockResponse(doc.Paths.Find("/pets/{id}").Get, "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, echo.MIMETextPlain, contentType)
+	assert.Equal(t, "rex", string(body))
+
+	status, _, body, err = mockResponse(doc.Paths.Find("/pets").Post, echo.MIMEApplicationJSON)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+
+	var pet map[string]any
+	require.NoError(t, json.Unmarshal(body, &pet))
+	assert.Equal(t, "rex", pet["name"])
+}
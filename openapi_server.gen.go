@@ -16,15 +16,78 @@ type ServerInterface interface {
 	// Root Endpoint
 	// (GET /)
 	Get(ctx echo.Context) error
+	// API Key Authentication
+	// (GET /auth/apikey/{key})
+	GetAuthApikeyKey(ctx echo.Context, key string, params GetAuthApikeyKeyParams) error
+	// Basic Authentication
+	// (GET /auth/basic/{user}/{password})
+	GetAuthBasicUserPassword(ctx echo.Context, user AuthUser, password AuthPassword, params GetAuthBasicUserPasswordParams) error
+	// Bearer Authentication
+	// (GET /auth/bearer)
+	GetAuthBearer(ctx echo.Context, params GetAuthBearerParams) error
+	// Digest Authentication
+	// (GET /auth/digest/{user}/{password})
+	GetAuthDigestUserPassword(ctx echo.Context, user AuthUser, password AuthPassword, params GetAuthDigestUserPasswordParams) error
+	// Get Cacheable Data
+	// (GET /cache/{size})
+	GetCacheSize(ctx echo.Context, size string, params GetCacheSizeParams) error
+	// Get Cookies
+	// (GET /cookies)
+	GetCookies(ctx echo.Context) error
+	// Delete Cookies
+	// (GET /cookies/delete)
+	GetCookiesDelete(ctx echo.Context, params GetCookiesDeleteParams) error
+	// Set Cookies
+	// (GET /cookies/set)
+	GetCookiesSet(ctx echo.Context, params GetCookiesSetParams) error
 	// Get Data
 	// (GET /data/{size})
-	GetDataSize(ctx echo.Context, size string) error
+	GetDataSize(ctx echo.Context, size string, params GetDataSizeParams) error
 	// Get Latency
 	// (GET /latency/{duration})
 	GetLatencyDuration(ctx echo.Context, duration string) error
+	// Metrics
+	// (GET /metrics)
+	GetMetrics(ctx echo.Context) error
+	// Rate Limited Endpoint
+	// (GET /ratelimit/{bucket})
+	GetRatelimitBucket(ctx echo.Context, bucket string, params GetRatelimitBucketParams) error
+	// Redirect Chain
+	// (DELETE /redirect/{hops})
+	DeleteRedirectHops(ctx echo.Context, hops RedirectHops, params DeleteRedirectHopsParams) error
+	// Redirect Chain
+	// (GET /redirect/{hops})
+	GetRedirectHops(ctx echo.Context, hops RedirectHops, params GetRedirectHopsParams) error
+	// Redirect Chain
+	// (PATCH /redirect/{hops})
+	PatchRedirectHops(ctx echo.Context, hops RedirectHops, params PatchRedirectHopsParams) error
+	// Redirect Chain
+	// (POST /redirect/{hops})
+	PostRedirectHops(ctx echo.Context, hops RedirectHops, params PostRedirectHopsParams) error
+	// Redirect Chain
+	// (PUT /redirect/{hops})
+	PutRedirectHops(ctx echo.Context, hops RedirectHops, params PutRedirectHopsParams) error
 	// Custom Response Endpoint
 	// (GET /response)
 	GetResponse(ctx echo.Context, params GetResponseParams) error
+	// List Resources
+	// (GET /store/{collection})
+	GetStoreCollection(ctx echo.Context, collection StoreCollection, params GetStoreCollectionParams) error
+	// Create Resource
+	// (POST /store/{collection})
+	PostStoreCollection(ctx echo.Context, collection StoreCollection, params PostStoreCollectionParams) error
+	// Delete Resource
+	// (DELETE /store/{collection}/{id})
+	DeleteStoreCollectionId(ctx echo.Context, collection StoreCollection, id string, params DeleteStoreCollectionIdParams) error
+	// Get Resource
+	// (GET /store/{collection}/{id})
+	GetStoreCollectionId(ctx echo.Context, collection StoreCollection, id string, params GetStoreCollectionIdParams) error
+	// Update Resource
+	// (PATCH /store/{collection}/{id})
+	PatchStoreCollectionId(ctx echo.Context, collection StoreCollection, id string, params PatchStoreCollectionIdParams) error
+	// Replace Resource
+	// (PUT /store/{collection}/{id})
+	PutStoreCollectionId(ctx echo.Context, collection StoreCollection, id string, params PutStoreCollectionIdParams) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -41,6 +104,346 @@ func (w *ServerInterfaceWrapper) Get(ctx echo.Context) error {
 	return err
 }
 
+// GetAuthApikeyKey converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuthApikeyKey(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "key", runtime.ParamLocationPath, ctx.Param("key"), &key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthApikeyKeyParams
+	// ------------- Optional query parameter "header" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "header", ctx.QueryParams(), &params.Header)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter header: %s", err))
+	}
+
+	// ------------- Optional query parameter "query" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "query", ctx.QueryParams(), &params.Query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter query: %s", err))
+	}
+
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAuthApikeyKey(ctx, key, params)
+	return err
+}
+
+// GetAuthBasicUserPassword converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuthBasicUserPassword(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "user" -------------
+	var user AuthUser
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "user", runtime.ParamLocationPath, ctx.Param("user"), &user)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user: %s", err))
+	}
+
+	// ------------- Path parameter "password" -------------
+	var password AuthPassword
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "password", runtime.ParamLocationPath, ctx.Param("password"), &password)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter password: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthBasicUserPasswordParams
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAuthBasicUserPassword(ctx, user, password, params)
+	return err
+}
+
+// GetAuthBearer converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuthBearer(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthBearerParams
+	// ------------- Optional query parameter "token" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "token", ctx.QueryParams(), &params.Token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAuthBearer(ctx, params)
+	return err
+}
+
+// GetAuthDigestUserPassword converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuthDigestUserPassword(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "user" -------------
+	var user AuthUser
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "user", runtime.ParamLocationPath, ctx.Param("user"), &user)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user: %s", err))
+	}
+
+	// ------------- Path parameter "password" -------------
+	var password AuthPassword
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "password", runtime.ParamLocationPath, ctx.Param("password"), &password)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter password: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthDigestUserPasswordParams
+	// ------------- Optional query parameter "qop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "qop", ctx.QueryParams(), &params.Qop)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter qop: %s", err))
+	}
+
+	// ------------- Optional query parameter "algorithm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "algorithm", ctx.QueryParams(), &params.Algorithm)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter algorithm: %s", err))
+	}
+
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAuthDigestUserPassword(ctx, user, password, params)
+	return err
+}
+
+// GetCacheSize converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCacheSize(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "size" -------------
+	var size string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "size", runtime.ParamLocationPath, ctx.Param("size"), &size)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter size: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCacheSizeParams
+	// ------------- Optional query parameter "max_age" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "max_age", ctx.QueryParams(), &params.MaxAge)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter max_age: %s", err))
+	}
+
+	// ------------- Optional query parameter "cache_control" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cache_control", ctx.QueryParams(), &params.CacheControl)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cache_control: %s", err))
+	}
+
+	// ------------- Optional query parameter "vary" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "vary", ctx.QueryParams(), &params.Vary)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter vary: %s", err))
+	}
+
+	// ------------- Optional query parameter "weak" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "weak", ctx.QueryParams(), &params.Weak)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter weak: %s", err))
+	}
+
+	// ------------- Optional query parameter "change_every" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "change_every", ctx.QueryParams(), &params.ChangeEvery)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter change_every: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetCacheSize(ctx, size, params)
+	return err
+}
+
+// GetCookies converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCookies(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetCookies(ctx)
+	return err
+}
+
+// GetCookiesDelete converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCookiesDelete(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCookiesDeleteParams
+	// ------------- Required query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "name", ctx.QueryParams(), &params.Name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// ------------- Optional query parameter "domain" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "domain", ctx.QueryParams(), &params.Domain)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter domain: %s", err))
+	}
+
+	// ------------- Optional query parameter "path" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "path", ctx.QueryParams(), &params.Path)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter path: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirect" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirect", ctx.QueryParams(), &params.Redirect)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirect: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetCookiesDelete(ctx, params)
+	return err
+}
+
+// GetCookiesSet converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCookiesSet(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCookiesSetParams
+	// ------------- Required query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "name", ctx.QueryParams(), &params.Name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// ------------- Optional query parameter "value" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "value", ctx.QueryParams(), &params.Value)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter value: %s", err))
+	}
+
+	// ------------- Optional query parameter "size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "size", ctx.QueryParams(), &params.Size)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter size: %s", err))
+	}
+
+	// ------------- Optional query parameter "count" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "count", ctx.QueryParams(), &params.Count)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter count: %s", err))
+	}
+
+	// ------------- Optional query parameter "domain" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "domain", ctx.QueryParams(), &params.Domain)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter domain: %s", err))
+	}
+
+	// ------------- Optional query parameter "path" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "path", ctx.QueryParams(), &params.Path)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter path: %s", err))
+	}
+
+	// ------------- Optional query parameter "expires" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "expires", ctx.QueryParams(), &params.Expires)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter expires: %s", err))
+	}
+
+	// ------------- Optional query parameter "max_age" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "max_age", ctx.QueryParams(), &params.MaxAge)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter max_age: %s", err))
+	}
+
+	// ------------- Optional query parameter "secure" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "secure", ctx.QueryParams(), &params.Secure)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter secure: %s", err))
+	}
+
+	// ------------- Optional query parameter "http_only" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "http_only", ctx.QueryParams(), &params.HttpOnly)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter http_only: %s", err))
+	}
+
+	// ------------- Optional query parameter "same_site" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "same_site", ctx.QueryParams(), &params.SameSite)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter same_site: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirect" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirect", ctx.QueryParams(), &params.Redirect)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirect: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetCookiesSet(ctx, params)
+	return err
+}
+
 // GetDataSize converts echo context to params.
 func (w *ServerInterfaceWrapper) GetDataSize(ctx echo.Context) error {
 	var err error
@@ -52,8 +455,17 @@ func (w *ServerInterfaceWrapper) GetDataSize(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter size: %s", err))
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDataSizeParams
+	// ------------- Optional query parameter "seed" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "seed", ctx.QueryParams(), &params.Seed)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter seed: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GetDataSize(ctx, size)
+	err = w.Handler.GetDataSize(ctx, size, params)
 	return err
 }
 
@@ -73,12 +485,74 @@ func (w *ServerInterfaceWrapper) GetLatencyDuration(ctx echo.Context) error {
 	return err
 }
 
-// GetResponse converts echo context to params.
-func (w *ServerInterfaceWrapper) GetResponse(ctx echo.Context) error {
+// GetMetrics converts echo context to params.
+func (w *ServerInterfaceWrapper) GetMetrics(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetMetrics(ctx)
+	return err
+}
+
+// GetRatelimitBucket converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRatelimitBucket(ctx echo.Context) error {
 	var err error
+	// ------------- Path parameter "bucket" -------------
+	var bucket string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "bucket", runtime.ParamLocationPath, ctx.Param("bucket"), &bucket)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter bucket: %s", err))
+	}
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params GetResponseParams
+	var params GetRatelimitBucketParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "window" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "window", ctx.QueryParams(), &params.Window)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter window: %s", err))
+	}
+
+	// ------------- Optional query parameter "algorithm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "algorithm", ctx.QueryParams(), &params.Algorithm)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter algorithm: %s", err))
+	}
+
+	// ------------- Optional query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "key", ctx.QueryParams(), &params.Key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRatelimitBucket(ctx, bucket, params)
+	return err
+}
+
+// DeleteRedirectHops converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteRedirectHops(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "hops" -------------
+	var hops RedirectHops
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "hops", runtime.ParamLocationPath, ctx.Param("hops"), &hops)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter hops: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteRedirectHopsParams
 	// ------------- Optional query parameter "status" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
@@ -86,8 +560,476 @@ func (w *ServerInterfaceWrapper) GetResponse(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
 	}
 
-	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GetResponse(ctx, params)
+	// ------------- Optional query parameter "absolute" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "absolute", ctx.QueryParams(), &params.Absolute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter absolute: %s", err))
+	}
+
+	// ------------- Optional query parameter "host" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "host", ctx.QueryParams(), &params.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter host: %s", err))
+	}
+
+	// ------------- Optional query parameter "loop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "loop", ctx.QueryParams(), &params.Loop)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter loop: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirects", ctx.QueryParams(), &params.Redirects)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirects: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteRedirectHops(ctx, hops, params)
+	return err
+}
+
+// GetRedirectHops converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRedirectHops(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "hops" -------------
+	var hops RedirectHops
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "hops", runtime.ParamLocationPath, ctx.Param("hops"), &hops)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter hops: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRedirectHopsParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "absolute" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "absolute", ctx.QueryParams(), &params.Absolute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter absolute: %s", err))
+	}
+
+	// ------------- Optional query parameter "host" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "host", ctx.QueryParams(), &params.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter host: %s", err))
+	}
+
+	// ------------- Optional query parameter "loop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "loop", ctx.QueryParams(), &params.Loop)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter loop: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirects", ctx.QueryParams(), &params.Redirects)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirects: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRedirectHops(ctx, hops, params)
+	return err
+}
+
+// PatchRedirectHops converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchRedirectHops(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "hops" -------------
+	var hops RedirectHops
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "hops", runtime.ParamLocationPath, ctx.Param("hops"), &hops)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter hops: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchRedirectHopsParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "absolute" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "absolute", ctx.QueryParams(), &params.Absolute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter absolute: %s", err))
+	}
+
+	// ------------- Optional query parameter "host" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "host", ctx.QueryParams(), &params.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter host: %s", err))
+	}
+
+	// ------------- Optional query parameter "loop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "loop", ctx.QueryParams(), &params.Loop)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter loop: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirects", ctx.QueryParams(), &params.Redirects)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirects: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PatchRedirectHops(ctx, hops, params)
+	return err
+}
+
+// PostRedirectHops converts echo context to params.
+func (w *ServerInterfaceWrapper) PostRedirectHops(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "hops" -------------
+	var hops RedirectHops
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "hops", runtime.ParamLocationPath, ctx.Param("hops"), &hops)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter hops: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostRedirectHopsParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "absolute" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "absolute", ctx.QueryParams(), &params.Absolute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter absolute: %s", err))
+	}
+
+	// ------------- Optional query parameter "host" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "host", ctx.QueryParams(), &params.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter host: %s", err))
+	}
+
+	// ------------- Optional query parameter "loop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "loop", ctx.QueryParams(), &params.Loop)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter loop: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirects", ctx.QueryParams(), &params.Redirects)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirects: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostRedirectHops(ctx, hops, params)
+	return err
+}
+
+// PutRedirectHops converts echo context to params.
+func (w *ServerInterfaceWrapper) PutRedirectHops(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "hops" -------------
+	var hops RedirectHops
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "hops", runtime.ParamLocationPath, ctx.Param("hops"), &hops)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter hops: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutRedirectHopsParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "absolute" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "absolute", ctx.QueryParams(), &params.Absolute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter absolute: %s", err))
+	}
+
+	// ------------- Optional query parameter "host" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "host", ctx.QueryParams(), &params.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter host: %s", err))
+	}
+
+	// ------------- Optional query parameter "loop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "loop", ctx.QueryParams(), &params.Loop)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter loop: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirects", ctx.QueryParams(), &params.Redirects)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirects: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PutRedirectHops(ctx, hops, params)
+	return err
+}
+
+// GetResponse converts echo context to params.
+func (w *ServerInterfaceWrapper) GetResponse(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetResponseParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "body" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "body", ctx.QueryParams(), &params.Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter body: %s", err))
+	}
+
+	// ------------- Optional query parameter "template" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "template", ctx.QueryParams(), &params.Template)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter template: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetResponse(ctx, params)
+	return err
+}
+
+// GetStoreCollection converts echo context to params.
+func (w *ServerInterfaceWrapper) GetStoreCollection(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "collection" -------------
+	var collection StoreCollection
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "collection", runtime.ParamLocationPath, ctx.Param("collection"), &collection)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter collection: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetStoreCollectionParams
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetStoreCollection(ctx, collection, params)
+	return err
+}
+
+// PostStoreCollection converts echo context to params.
+func (w *ServerInterfaceWrapper) PostStoreCollection(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "collection" -------------
+	var collection StoreCollection
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "collection", runtime.ParamLocationPath, ctx.Param("collection"), &collection)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter collection: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostStoreCollectionParams
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostStoreCollection(ctx, collection, params)
+	return err
+}
+
+// DeleteStoreCollectionId converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteStoreCollectionId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "collection" -------------
+	var collection StoreCollection
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "collection", runtime.ParamLocationPath, ctx.Param("collection"), &collection)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter collection: %s", err))
+	}
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteStoreCollectionIdParams
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteStoreCollectionId(ctx, collection, id, params)
+	return err
+}
+
+// GetStoreCollectionId converts echo context to params.
+func (w *ServerInterfaceWrapper) GetStoreCollectionId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "collection" -------------
+	var collection StoreCollection
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "collection", runtime.ParamLocationPath, ctx.Param("collection"), &collection)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter collection: %s", err))
+	}
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetStoreCollectionIdParams
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetStoreCollectionId(ctx, collection, id, params)
+	return err
+}
+
+// PatchStoreCollectionId converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchStoreCollectionId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "collection" -------------
+	var collection StoreCollection
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "collection", runtime.ParamLocationPath, ctx.Param("collection"), &collection)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter collection: %s", err))
+	}
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchStoreCollectionIdParams
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PatchStoreCollectionId(ctx, collection, id, params)
+	return err
+}
+
+// PutStoreCollectionId converts echo context to params.
+func (w *ServerInterfaceWrapper) PutStoreCollectionId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "collection" -------------
+	var collection StoreCollection
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "collection", runtime.ParamLocationPath, ctx.Param("collection"), &collection)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter collection: %s", err))
+	}
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutStoreCollectionIdParams
+	// ------------- Optional query parameter "latency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latency", ctx.QueryParams(), &params.Latency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter latency: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PutStoreCollectionId(ctx, collection, id, params)
 	return err
 }
 
@@ -119,7 +1061,28 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	}
 
 	router.GET(baseURL+"/", wrapper.Get)
+	router.GET(baseURL+"/auth/apikey/:key", wrapper.GetAuthApikeyKey)
+	router.GET(baseURL+"/auth/basic/:user/:password", wrapper.GetAuthBasicUserPassword)
+	router.GET(baseURL+"/auth/bearer", wrapper.GetAuthBearer)
+	router.GET(baseURL+"/auth/digest/:user/:password", wrapper.GetAuthDigestUserPassword)
+	router.GET(baseURL+"/cache/:size", wrapper.GetCacheSize)
+	router.GET(baseURL+"/cookies", wrapper.GetCookies)
+	router.GET(baseURL+"/cookies/delete", wrapper.GetCookiesDelete)
+	router.GET(baseURL+"/cookies/set", wrapper.GetCookiesSet)
 	router.GET(baseURL+"/data/:size", wrapper.GetDataSize)
 	router.GET(baseURL+"/latency/:duration", wrapper.GetLatencyDuration)
+	router.GET(baseURL+"/metrics", wrapper.GetMetrics)
+	router.GET(baseURL+"/ratelimit/:bucket", wrapper.GetRatelimitBucket)
+	router.DELETE(baseURL+"/redirect/:hops", wrapper.DeleteRedirectHops)
+	router.GET(baseURL+"/redirect/:hops", wrapper.GetRedirectHops)
+	router.PATCH(baseURL+"/redirect/:hops", wrapper.PatchRedirectHops)
+	router.POST(baseURL+"/redirect/:hops", wrapper.PostRedirectHops)
+	router.PUT(baseURL+"/redirect/:hops", wrapper.PutRedirectHops)
 	router.GET(baseURL+"/response", wrapper.GetResponse)
+	router.GET(baseURL+"/store/:collection", wrapper.GetStoreCollection)
+	router.POST(baseURL+"/store/:collection", wrapper.PostStoreCollection)
+	router.DELETE(baseURL+"/store/:collection/:id", wrapper.DeleteStoreCollectionId)
+	router.GET(baseURL+"/store/:collection/:id", wrapper.GetStoreCollectionId)
+	router.PATCH(baseURL+"/store/:collection/:id", wrapper.PatchStoreCollectionId)
+	router.PUT(baseURL+"/store/:collection/:id", wrapper.PutStoreCollectionId)
 }
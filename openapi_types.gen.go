@@ -3,8 +3,391 @@
 // Code generated by github.com/deepmap/oapi-codegen/v2 version v2.0.0 DO NOT EDIT.
 package main
 
+// Cookies defines model for Cookies.
+type Cookies map[string]string
+
+// Identity defines model for Identity.
+type Identity struct {
+	// Authenticated Whether the client was authenticated.
+	Authenticated bool `json:"authenticated"`
+
+	// Identity Identity the client was authenticated as, either a user name, a token or an API key.
+	Identity string `json:"identity"`
+
+	// Scheme Authentication scheme the client was authenticated with.
+	Scheme string `json:"scheme"`
+}
+
+// RateLimitStatus defines model for RateLimitStatus.
+type RateLimitStatus struct {
+	// Bucket Name of the bucket the request was counted in.
+	Bucket string `json:"bucket"`
+
+	// Client Key identifying the client the request was counted for.
+	Client string `json:"client"`
+
+	// Limit Number of requests allowed per window.
+	Limit int `json:"limit"`
+
+	// Remaining Number of requests remaining in the current window.
+	Remaining int `json:"remaining"`
+
+	// Reset Number of seconds until the quota resets.
+	Reset int `json:"reset"`
+}
+
+// RedirectDestination defines model for RedirectDestination.
+type RedirectDestination struct {
+	// Body Body of the request that reached the destination.
+	Body *string `json:"body,omitempty"`
+
+	// ContentType Content-Type of the request that reached the destination.
+	ContentType *string `json:"content_type,omitempty"`
+
+	// Method Method of the request that reached the destination.
+	Method *string `json:"method,omitempty"`
+
+	// Redirects Number of redirects followed to reach the destination.
+	Redirects *int `json:"redirects,omitempty"`
+}
+
+// Resource A JSON object, whose id property holds the resource's identifier.
+type Resource map[string]interface{}
+
+// ResourcePage defines model for ResourcePage.
+type ResourcePage struct {
+	Items []Resource `json:"items"`
+
+	// Limit Maximum number of resources returned.
+	Limit int `json:"limit"`
+
+	// Offset Number of resources skipped.
+	Offset int `json:"offset"`
+
+	// Total Total number of resources in the collection.
+	Total int `json:"total"`
+}
+
+// AuthPassword defines model for AuthPassword.
+type AuthPassword = string
+
+// AuthUser defines model for AuthUser.
+type AuthUser = string
+
+// CookiesRedirect defines model for CookiesRedirect.
+type CookiesRedirect = string
+
+// QueryLatency defines model for QueryLatency.
+type QueryLatency = string
+
+// RedirectAbsolute defines model for RedirectAbsolute.
+type RedirectAbsolute = bool
+
+// RedirectHops defines model for RedirectHops.
+type RedirectHops = int
+
+// RedirectHost defines model for RedirectHost.
+type RedirectHost = string
+
+// RedirectLoop defines model for RedirectLoop.
+type RedirectLoop = bool
+
+// RedirectRedirects defines model for RedirectRedirects.
+type RedirectRedirects = int
+
+// RedirectStatus defines model for RedirectStatus.
+type RedirectStatus = int
+
+// StoreCollection defines model for StoreCollection.
+type StoreCollection = string
+
+// Authenticated defines model for Authenticated.
+type Authenticated = Identity
+
+// GetAuthApikeyKeyParams defines parameters for GetAuthApikeyKey.
+type GetAuthApikeyKeyParams struct {
+	// Header Name of the header holding the API key.
+	Header *string `form:"header,omitempty" json:"header,omitempty"`
+
+	// Query Name of the query parameter holding the API key.
+	Query *string `form:"query,omitempty" json:"query,omitempty"`
+
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// GetAuthBasicUserPasswordParams defines parameters for GetAuthBasicUserPassword.
+type GetAuthBasicUserPasswordParams struct {
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// GetAuthBearerParams defines parameters for GetAuthBearer.
+type GetAuthBearerParams struct {
+	// Token Expected token. Any token is accepted when omitted.
+	Token *string `form:"token,omitempty" json:"token,omitempty"`
+
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// GetAuthDigestUserPasswordParams defines parameters for GetAuthDigestUserPassword.
+type GetAuthDigestUserPasswordParams struct {
+	// Qop Quality of protection, one of auth, auth-int or none.
+	Qop *string `form:"qop,omitempty" json:"qop,omitempty"`
+
+	// Algorithm Hashing algorithm, one of MD5 or SHA-256.
+	Algorithm *string `form:"algorithm,omitempty" json:"algorithm,omitempty"`
+
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// GetCacheSizeParams defines parameters for GetCacheSize.
+type GetCacheSizeParams struct {
+	// MaxAge Number of seconds the response is fresh for, as the Cache-Control max-age directive.
+	MaxAge *int `form:"max_age,omitempty" json:"max_age,omitempty"`
+
+	// CacheControl Cache-Control header of the response, replacing the one derived from max_age.
+	CacheControl *string `form:"cache_control,omitempty" json:"cache_control,omitempty"`
+
+	// Vary Vary header of the response.
+	Vary *string `form:"vary,omitempty" json:"vary,omitempty"`
+
+	// Weak Whether the ETag is a weak validator.
+	Weak *bool `form:"weak,omitempty" json:"weak,omitempty"`
+
+	// ChangeEvery Interval at which the payload, its ETag and its Last-Modified date change (e.g. 30s). The payload never changes by default.
+	ChangeEvery *string `form:"change_every,omitempty" json:"change_every,omitempty"`
+}
+
+// GetCookiesDeleteParams defines parameters for GetCookiesDelete.
+type GetCookiesDeleteParams struct {
+	// Name Names of the cookies to delete.
+	Name []string `form:"name" json:"name"`
+
+	// Domain Domain attribute of the cookies to delete.
+	Domain *string `form:"domain,omitempty" json:"domain,omitempty"`
+
+	// Path Path attribute of the cookies to delete.
+	Path *string `form:"path,omitempty" json:"path,omitempty"`
+
+	// Redirect Location to redirect to once the cookies are handled.
+	Redirect *CookiesRedirect `form:"redirect,omitempty" json:"redirect,omitempty"`
+}
+
+// GetCookiesSetParams defines parameters for GetCookiesSet.
+type GetCookiesSetParams struct {
+	// Name Name of the cookie. When setting multiple cookies, their names are suffixed with their index.
+	Name string `form:"name" json:"name"`
+
+	// Value Value of the cookie.
+	Value *string `form:"value,omitempty" json:"value,omitempty"`
+
+	// Size Size of a generated value for the cookie, overriding the value parameter.
+	Size *string `form:"size,omitempty" json:"size,omitempty"`
+
+	// Count Number of cookies to set.
+	Count *int `form:"count,omitempty" json:"count,omitempty"`
+
+	// Domain Domain attribute of the cookie.
+	Domain *string `form:"domain,omitempty" json:"domain,omitempty"`
+
+	// Path Path attribute of the cookie.
+	Path *string `form:"path,omitempty" json:"path,omitempty"`
+
+	// Expires Expires attribute of the cookie, either as an HTTP date or as a duration from now.
+	Expires *string `form:"expires,omitempty" json:"expires,omitempty"`
+
+	// MaxAge Max-Age attribute of the cookie, in seconds.
+	MaxAge *int `form:"max_age,omitempty" json:"max_age,omitempty"`
+
+	// Secure Secure attribute of the cookie.
+	Secure *bool `form:"secure,omitempty" json:"secure,omitempty"`
+
+	// HttpOnly HttpOnly attribute of the cookie.
+	HttpOnly *bool `form:"http_only,omitempty" json:"http_only,omitempty"`
+
+	// SameSite SameSite attribute of the cookie, one of lax, strict or none.
+	SameSite *string `form:"same_site,omitempty" json:"same_site,omitempty"`
+
+	// Redirect Location to redirect to once the cookies are handled.
+	Redirect *CookiesRedirect `form:"redirect,omitempty" json:"redirect,omitempty"`
+}
+
+// GetDataSizeParams defines parameters for GetDataSize.
+type GetDataSizeParams struct {
+	// Seed Seed the payload is generated from. Payloads generated from the same size and seed are identical, which allows resuming downloads using range requests. By default, the seed is derived from the size.
+	Seed *int64 `form:"seed,omitempty" json:"seed,omitempty"`
+}
+
+// GetRatelimitBucketParams defines parameters for GetRatelimitBucket.
+type GetRatelimitBucketParams struct {
+	// Limit Number of requests allowed per window.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Window Duration over which the limit applies.
+	Window *string `form:"window,omitempty" json:"window,omitempty"`
+
+	// Algorithm Algorithm enforcing the limit, one of token-bucket or sliding-window.
+	Algorithm *string `form:"algorithm,omitempty" json:"algorithm,omitempty"`
+
+	// Key What identifies clients, one of ip, apikey or header:<name>.
+	Key *string `form:"key,omitempty" json:"key,omitempty"`
+}
+
+// DeleteRedirectHopsParams defines parameters for DeleteRedirectHops.
+type DeleteRedirectHopsParams struct {
+	// Status HTTP status code of the redirects, one of 301, 302, 303, 307 or 308.
+	Status *RedirectStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Absolute Whether the Location header holds an absolute URL rather than a relative one.
+	Absolute *RedirectAbsolute `form:"absolute,omitempty" json:"absolute,omitempty"`
+
+	// Host Host the redirects point to, producing absolute cross-host redirects.
+	Host *RedirectHost `form:"host,omitempty" json:"host,omitempty"`
+
+	// Loop Whether the redirect points back to itself, producing an endless loop.
+	Loop *RedirectLoop `form:"loop,omitempty" json:"loop,omitempty"`
+
+	// Redirects Number of redirects followed so far, maintained by the server along the chain.
+	Redirects *RedirectRedirects `form:"redirects,omitempty" json:"redirects,omitempty"`
+}
+
+// GetRedirectHopsParams defines parameters for GetRedirectHops.
+type GetRedirectHopsParams struct {
+	// Status HTTP status code of the redirects, one of 301, 302, 303, 307 or 308.
+	Status *RedirectStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Absolute Whether the Location header holds an absolute URL rather than a relative one.
+	Absolute *RedirectAbsolute `form:"absolute,omitempty" json:"absolute,omitempty"`
+
+	// Host Host the redirects point to, producing absolute cross-host redirects.
+	Host *RedirectHost `form:"host,omitempty" json:"host,omitempty"`
+
+	// Loop Whether the redirect points back to itself, producing an endless loop.
+	Loop *RedirectLoop `form:"loop,omitempty" json:"loop,omitempty"`
+
+	// Redirects Number of redirects followed so far, maintained by the server along the chain.
+	Redirects *RedirectRedirects `form:"redirects,omitempty" json:"redirects,omitempty"`
+}
+
+// PatchRedirectHopsParams defines parameters for PatchRedirectHops.
+type PatchRedirectHopsParams struct {
+	// Status HTTP status code of the redirects, one of 301, 302, 303, 307 or 308.
+	Status *RedirectStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Absolute Whether the Location header holds an absolute URL rather than a relative one.
+	Absolute *RedirectAbsolute `form:"absolute,omitempty" json:"absolute,omitempty"`
+
+	// Host Host the redirects point to, producing absolute cross-host redirects.
+	Host *RedirectHost `form:"host,omitempty" json:"host,omitempty"`
+
+	// Loop Whether the redirect points back to itself, producing an endless loop.
+	Loop *RedirectLoop `form:"loop,omitempty" json:"loop,omitempty"`
+
+	// Redirects Number of redirects followed so far, maintained by the server along the chain.
+	Redirects *RedirectRedirects `form:"redirects,omitempty" json:"redirects,omitempty"`
+}
+
+// PostRedirectHopsParams defines parameters for PostRedirectHops.
+type PostRedirectHopsParams struct {
+	// Status HTTP status code of the redirects, one of 301, 302, 303, 307 or 308.
+	Status *RedirectStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Absolute Whether the Location header holds an absolute URL rather than a relative one.
+	Absolute *RedirectAbsolute `form:"absolute,omitempty" json:"absolute,omitempty"`
+
+	// Host Host the redirects point to, producing absolute cross-host redirects.
+	Host *RedirectHost `form:"host,omitempty" json:"host,omitempty"`
+
+	// Loop Whether the redirect points back to itself, producing an endless loop.
+	Loop *RedirectLoop `form:"loop,omitempty" json:"loop,omitempty"`
+
+	// Redirects Number of redirects followed so far, maintained by the server along the chain.
+	Redirects *RedirectRedirects `form:"redirects,omitempty" json:"redirects,omitempty"`
+}
+
+// PutRedirectHopsParams defines parameters for PutRedirectHops.
+type PutRedirectHopsParams struct {
+	// Status HTTP status code of the redirects, one of 301, 302, 303, 307 or 308.
+	Status *RedirectStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Absolute Whether the Location header holds an absolute URL rather than a relative one.
+	Absolute *RedirectAbsolute `form:"absolute,omitempty" json:"absolute,omitempty"`
+
+	// Host Host the redirects point to, producing absolute cross-host redirects.
+	Host *RedirectHost `form:"host,omitempty" json:"host,omitempty"`
+
+	// Loop Whether the redirect points back to itself, producing an endless loop.
+	Loop *RedirectLoop `form:"loop,omitempty" json:"loop,omitempty"`
+
+	// Redirects Number of redirects followed so far, maintained by the server along the chain.
+	Redirects *RedirectRedirects `form:"redirects,omitempty" json:"redirects,omitempty"`
+}
+
 // GetResponseParams defines parameters for GetResponse.
 type GetResponseParams struct {
 	// Status HTTP status code of the response.
 	Status *int `form:"status,omitempty" json:"status,omitempty"`
+
+	// Body Body of the response, replacing the default one.
+	Body *string `form:"body,omitempty" json:"body,omitempty"`
+
+	// Template Render the body as a Go text/template, with access to the request's path, query, headers and body.
+	Template *bool `form:"template,omitempty" json:"template,omitempty"`
+}
+
+// GetStoreCollectionParams defines parameters for GetStoreCollection.
+type GetStoreCollectionParams struct {
+	// Offset Number of resources to skip.
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Limit Maximum number of resources to return.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// PostStoreCollectionParams defines parameters for PostStoreCollection.
+type PostStoreCollectionParams struct {
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// DeleteStoreCollectionIdParams defines parameters for DeleteStoreCollectionId.
+type DeleteStoreCollectionIdParams struct {
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// GetStoreCollectionIdParams defines parameters for GetStoreCollectionId.
+type GetStoreCollectionIdParams struct {
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
 }
+
+// PatchStoreCollectionIdParams defines parameters for PatchStoreCollectionId.
+type PatchStoreCollectionIdParams struct {
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// PutStoreCollectionIdParams defines parameters for PutStoreCollectionId.
+type PutStoreCollectionIdParams struct {
+	// Latency Latency to wait for before responding, using the same format as /latency/{duration}.
+	Latency *QueryLatency `form:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// PostStoreCollectionJSONRequestBody defines body for PostStoreCollection for application/json ContentType.
+type PostStoreCollectionJSONRequestBody = Resource
+
+// PatchStoreCollectionIdJSONRequestBody defines body for PatchStoreCollectionId for application/json ContentType.
+type PatchStoreCollectionIdJSONRequestBody = Resource
+
+// PatchStoreCollectionIdApplicationMergePatchPlusJSONRequestBody defines body for PatchStoreCollectionId for application/merge-patch+json ContentType.
+type PatchStoreCollectionIdApplicationMergePatchPlusJSONRequestBody = Resource
+
+// PutStoreCollectionIdJSONRequestBody defines body for PutStoreCollectionId for application/json ContentType.
+type PutStoreCollectionIdJSONRequestBody = Resource
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// ProxyOptions holds the options of the reverse proxy mode.
+type ProxyOptions struct {
+	// Upstream is the URL of the service the requests are forwarded to.
+	// An empty upstream disables the reverse proxy mode.
+	Upstream string
+
+	// RulesFile is the path of the file defining the faults injected into
+	// the forwarded requests, route by route, if any.
+	RulesFile string
+}
+
+// Enabled returns true if the reverse proxy mode applies.
+func (o ProxyOptions) Enabled() bool {
+	return o.Upstream != ""
+}
+
+// Validate checks if the ProxyOptions struct satisfies the defined constraints.
+func (o ProxyOptions) Validate() error {
+	if !o.Enabled() {
+		if o.RulesFile != "" {
+			return errors.New("rules require an upstream")
+		}
+
+		return nil
+	}
+
+	upstream, err := url.Parse(o.Upstream)
+	if err != nil {
+		return fmt.Errorf("invalid upstream: %w", err)
+	}
+
+	if (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+		return fmt.Errorf("upstream %q must be an absolute http or https URL", o.Upstream)
+	}
+
+	return nil
+}
+
+// ProxyFault is a connection fault injected by the reverse proxy.
+type ProxyFault string
+
+const (
+	// ProxyFaultReset resets the connection, rather than forwarding the request.
+	ProxyFaultReset ProxyFault = "reset"
+
+	// ProxyFaultClose closes the connection, rather than forwarding the request.
+	ProxyFaultClose ProxyFault = "close"
+
+	// ProxyFaultTruncate forwards the request, and closes the connection
+	// halfway through the upstream response's body.
+	ProxyFaultTruncate ProxyFault = "truncate"
+)
+
+// ProxyStatusOverride answers a share of the requests with a status, rather
+// than forwarding them.
+type ProxyStatusOverride struct {
+	// Status is the status the requests are answered with.
+	Status int `json:"status" yaml:"status"`
+
+	// Rate is the probability, between 0 and 1, for a request to be
+	// answered with the status.
+	Rate float64 `json:"rate" yaml:"rate"`
+}
+
+// ProxyFaultRate injects a connection fault into a share of the requests.
+type ProxyFaultRate struct {
+	// Type is the fault injected: reset, close or truncate.
+	Type ProxyFault `json:"type" yaml:"type"`
+
+	// Rate is the probability, between 0 and 1, for the fault to be injected.
+	Rate float64 `json:"rate" yaml:"rate"`
+}
+
+// ProxyRulesFile describes the faults injected by the reverse proxy, as
+// found in a proxy rules file.
+type ProxyRulesFile struct {
+	Rules []ProxyRule `json:"rules" yaml:"rules"`
+}
+
+// ProxyRule describes the faults injected into the forwarded requests
+// satisfying its matcher.
+type ProxyRule struct {
+	// Request describes the requests the rule applies to.
+	Request RequestMatcher `json:"request" yaml:"request"`
+
+	// Latency is waited for before forwarding the request.
+	Latency Latency `json:"latency" yaml:"latency"`
+
+	// StatusOverrides answer a share of the requests with a status, rather
+	// than forwarding them. Their rates add up, and cannot exceed 1.
+	StatusOverrides []ProxyStatusOverride `json:"status_overrides,omitempty" yaml:"status_overrides,omitempty"`
+
+	// Faults inject connection faults into a share of the requests. Their
+	// rates add up, and cannot exceed 1.
+	Faults []ProxyFaultRate `json:"faults,omitempty" yaml:"faults,omitempty"`
+
+	// Bandwidth caps the transfer rate of the upstream responses' bodies.
+	Bandwidth Bandwidth `json:"bandwidth,omitempty" yaml:"bandwidth,omitempty"`
+}
+
+// Validate checks if the ProxyRule struct satisfies the defined constraints,
+// and compiles its matcher.
+func (r *ProxyRule) Validate() error {
+	if err := r.Request.Compile(); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	if err := r.Latency.Validate(); err != nil {
+		return fmt.Errorf("invalid latency: %w", err)
+	}
+
+	var statusRate float64
+	for _, override := range r.StatusOverrides {
+		if override.Status < 100 || override.Status > 599 {
+			return errors.New("status overrides' status must be between 100 and 599")
+		}
+
+		if override.Rate < 0 {
+			return errors.New("status overrides' rate cannot be negative")
+		}
+		statusRate += override.Rate
+	}
+
+	if statusRate > 1 {
+		return errors.New("status overrides' rates cannot add up to more than 1")
+	}
+
+	var faultRate float64
+	for _, fault := range r.Faults {
+		switch fault.Type {
+		case ProxyFaultReset, ProxyFaultClose, ProxyFaultTruncate:
+		default:
+			return fmt.Errorf("unsupported fault %q, expected reset, close or truncate", fault.Type)
+		}
+
+		if fault.Rate < 0 {
+			return errors.New("faults' rate cannot be negative")
+		}
+		faultRate += fault.Rate
+	}
+
+	if faultRate > 1 {
+		return errors.New("faults' rates cannot add up to more than 1")
+	}
+
+	return nil
+}
+
+// ParseProxyRules parses a proxy rules file, in either YAML or JSON, and
+// validates the rules it defines.
+//
+// The returned error describes every invalid rule, identified by its
+// position in the file.
+func ParseProxyRules(r io.Reader) ([]ProxyRule, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file ProxyRulesFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed parsing proxy rules: %w", err)
+	}
+
+	var errs []error
+	for i := range file.Rules {
+		if err := file.Rules[i].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", i+1, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid proxy rules: %w", errors.Join(errs...))
+	}
+
+	return file.Rules, nil
+}
+
+// Proxy forwards requests to an upstream service, injecting the faults of
+// the first rule matching each of them.
+type Proxy struct {
+	rules   []ProxyRule
+	handler *httputil.ReverseProxy
+}
+
+// NewProxy creates a new Proxy instance forwarding requests to the upstream
+// of the options, and injecting the faults of the rules.
+func NewProxy(options ProxyOptions, rules []ProxyRule) (*Proxy, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	upstream, err := url.Parse(options.Upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+			slog.Error(
+				"Failed to forward request",
+				"handler", "Proxy",
+				"upstream", upstream.String(),
+				"error_message", err.Error(),
+			)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+		ModifyResponse: func(res *http.Response) error {
+			if _, truncated := res.Request.Context().Value(truncatedResponseKey{}).(struct{}); truncated {
+				remaining := res.ContentLength / 2
+				if res.ContentLength < 0 {
+					remaining = ProxyTruncatedUnknownLength
+				}
+				res.Body = &truncatedBody{ReadCloser: res.Body, remaining: remaining}
+			}
+
+			return nil
+		},
+	}
+
+	return &Proxy{rules: rules, handler: handler}, nil
+}
+
+// match returns the first rule matching the request, and false if none does.
+func (p *Proxy) match(req CapturedRequest) (ProxyRule, bool) {
+	for _, rule := range p.rules {
+		if rule.Request.Match(req) {
+			return rule, true
+		}
+	}
+
+	return ProxyRule{}, false
+}
+
+// needsBody returns true if matching the request against the rules depends
+// on its body.
+func (p *Proxy) needsBody(req CapturedRequest) bool {
+	for _, rule := range p.rules {
+		if rule.Request.NeedsBody(req) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serve forwards the request to the upstream, injecting the faults of the
+// first rule matching it.
+func (p *Proxy) serve(ctx echo.Context) error {
+	req := ctx.Request()
+	if len(p.rules) == 0 {
+		p.handler.ServeHTTP(ctx.Response(), req)
+		return nil
+	}
+
+	matched, err := newMatchedRequest(req, p.needsBody)
+	if err != nil {
+		return err
+	}
+
+	rule, ok := p.match(matched)
+	if !ok {
+		p.handler.ServeHTTP(ctx.Response(), req)
+		return nil
+	}
+
+	rule.Latency.Wait()
+
+	if status, override := rule.drawStatus(); override {
+		return ctx.String(status, "injected error")
+	}
+
+	switch fault := rule.drawFault(); fault {
+	case ProxyFaultReset, ProxyFaultClose:
+		return dropConnection(ctx, fault == ProxyFaultReset)
+	case ProxyFaultTruncate:
+		req = req.WithContext(context.WithValue(req.Context(), truncatedResponseKey{}, struct{}{}))
+	default:
+	}
+
+	if rule.Bandwidth > 0 {
+		ctx.Response().Writer = newThrottledWriter(ctx.Response().Writer, rule.Bandwidth)
+	}
+
+	p.handler.ServeHTTP(ctx.Response(), req)
+
+	return nil
+}
+
+// drawStatus draws whether the request is answered with one of the status
+// overrides, and returns its status.
+//
+//nolint:gosec
+func (r ProxyRule) drawStatus() (int, bool) {
+	draw := rand.Float64()
+	for _, override := range r.StatusOverrides {
+		if draw < override.Rate {
+			return override.Status, true
+		}
+		draw -= override.Rate
+	}
+
+	return 0, false
+}
+
+// drawFault draws which of the faults, if any, is injected into the request.
+//
+//nolint:gosec
+func (r ProxyRule) drawFault() ProxyFault {
+	draw := rand.Float64()
+	for _, fault := range r.Faults {
+		if draw < fault.Rate {
+			return fault.Type
+		}
+		draw -= fault.Rate
+	}
+
+	return ""
+}
+
+// ProxyTruncatedUnknownLength is the number of bytes of an upstream response
+// body of unknown length, such as a chunked one, forwarded before the
+// truncate fault cuts it.
+const ProxyTruncatedUnknownLength = 1024
+
+// truncatedResponseKey is the context key marking the requests whose
+// upstream response is truncated.
+type truncatedResponseKey struct{}
+
+// errTruncatedBody is returned by truncated bodies once truncated.
+var errTruncatedBody = errors.New("response body truncated")
+
+// truncatedBody is an upstream response's body failing halfway through.
+// Bodies ending before they are truncated fail at their end instead, so the
+// response is never completed.
+//
+// The reverse proxy aborts the request on the failure, closing the
+// connection without completing the response.
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads from the body, until it is truncated.
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, errTruncatedBody
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if errors.Is(err, io.EOF) {
+		err = errTruncatedBody
+	}
+
+	return n, err
+}
+
+// dropConnection closes the request's connection without responding,
+// resetting it rather than closing it gracefully if reset is true. Requests
+// whose connection cannot be dropped, such as HTTP/2 ones, are answered with
+// a 502.
+func dropConnection(ctx echo.Context, reset bool) error {
+	conn, _, err := http.NewResponseController(ctx.Response().Writer).Hijack()
+	if err != nil {
+		return ctx.String(http.StatusBadGateway, "connection fault")
+	}
+
+	if reset {
+		if tcp, ok := unwrapConn(conn).(*net.TCPConn); ok {
+			_ = tcp.SetLinger(0)
+		}
+	}
+
+	return conn.Close()
+}
+
+// unwrapConn returns the innermost connection wrapped by conn, such as the
+// TCP connection a TLS one runs over.
+func unwrapConn(conn net.Conn) net.Conn {
+	for {
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return conn
+		}
+		conn = wrapper.NetConn()
+	}
+}
+
+// ProxyMiddleware returns a middleware forwarding every request whose path
+// does not start with one of the skipped prefixes to the proxy's upstream,
+// rather than handling it.
+func ProxyMiddleware(proxy *Proxy, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if hasAnyPathPrefix(ctx.Request().URL.Path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			return proxy.serve(ctx)
+		}
+	}
+}
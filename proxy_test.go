@@ -0,0 +1,278 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options ProxyOptions
+		wantErr bool
+	}{
+		{"disabled proxy should be valid", ProxyOptions{}, false},
+		{"absolute upstream should be valid", ProxyOptions{Upstream: "http://localhost:8000", RulesFile: "rules.yaml"}, false},
+		{"relative upstream should fail", ProxyOptions{Upstream: "localhost:8000"}, true},
+		{"unsupported upstream scheme should fail", ProxyOptions{Upstream: "ftp://localhost"}, true},
+		{"rules without upstream should fail", ProxyOptions{RulesFile: "rules.yaml"}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantErr, tt.options.Validate() != nil)
+		})
+	}
+}
+
+func TestParseProxyRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseProxyRules(strings.NewReader(`
+rules:
+  - request:
+      method: GET
+      path: /users/.*
+    latency: 100ms-200ms
+    status_overrides:
+      - status: 503
+        rate: 0.1
+    faults:
+      - type: reset
+        rate: 0.01
+    bandwidth: 64kb
+`))
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, Latency{LowerBound: 100 * time.Millisecond, UpperBound: 200 * time.Millisecond}, rules[0].Latency)
+	assert.Equal(t, []ProxyStatusOverride{{Status: 503, Rate: 0.1}}, rules[0].StatusOverrides)
+	assert.Equal(t, []ProxyFaultRate{{Type: ProxyFaultReset, Rate: 0.01}}, rules[0].Faults)
+	assert.Equal(t, Bandwidth(64*1024), rules[0].Bandwidth)
+	assert.True(t, rules[0].Request.Match(CapturedRequest{Method: http.MethodGet, Path: "/users/1"}), "matcher should be compiled")
+
+	_, err = ParseProxyRules(strings.NewReader(`
+rules:
+  - status_overrides: [{status: 503, rate: 0.6}, {status: 500, rate: 0.6}]
+  - faults: [{type: explode, rate: 0.1}]
+`))
+	assert.ErrorContains(t, err, "rule 1: status overrides' rates cannot add up to more than 1")
+	assert.ErrorContains(t, err, `rule 2: unsupported fault "explode"`)
+}
+
+// newProxyTestServer returns a server forwarding requests, with the proxy
+// rules applied, to an upstream responding with a 1kb body.
+func newProxyTestServer(t *testing.T, rules ...ProxyRule) *httptest.Server {
+	t.Helper()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream-Path", r.URL.Path)
+		switch r.URL.Path {
+		case "/chunked":
+			// Flushing before the end sends the body chunked, of unknown length
+			for i := 0; i < 4; i++ {
+				_, _ = w.Write([]byte(strings.Repeat("a", 1024)))
+				w.(http.Flusher).Flush()
+			}
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(body)
+		default:
+			_, _ = w.Write([]byte(strings.Repeat("a", 1024)))
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	for i := range rules {
+		require.NoError(t, rules[i].Validate())
+	}
+
+	proxy, err := NewProxy(ProxyOptions{Upstream: upstream.URL}, rules)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(middleware.Recover())
+	e.Use(ProxyMiddleware(proxy, DefaultAdminPrefix))
+	e.GET(DefaultAdminPrefix+"/settings", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestProxyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	server := newProxyTestServer(t,
+		ProxyRule{Request: RequestMatcher{Path: "/slow"}, Latency: Latency{LowerBound: 50 * time.Millisecond}},
+		ProxyRule{Request: RequestMatcher{Path: "/broken"}, StatusOverrides: []ProxyStatusOverride{{Status: http.StatusServiceUnavailable, Rate: 1}}},
+	)
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantBody    bool
+		wantLatency time.Duration
+	}{
+		{name: "unmatched request should be forwarded", path: "/users", wantStatus: http.StatusOK, wantBody: true},
+		{name: "matched request should be delayed", path: "/slow", wantStatus: http.StatusOK, wantBody: true, wantLatency: 50 * time.Millisecond},
+		{name: "status override should answer the request", path: "/broken", wantStatus: http.StatusServiceUnavailable},
+		{name: "skipped prefix should not be forwarded", path: DefaultAdminPrefix + "/settings", wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			res, err := http.Get(server.URL + tt.path) //nolint:noctx
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.GreaterOrEqual(t, time.Since(start), tt.wantLatency)
+			if tt.wantBody {
+				assert.Equal(t, tt.path, res.Header.Get("X-Upstream-Path"))
+				assert.Len(t, body, 1024)
+			} else {
+				assert.Empty(t, res.Header.Get("X-Upstream-Path"))
+			}
+		})
+	}
+}
+
+func TestProxyMiddleware_Faults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		fault ProxyFault
+	}{
+		{"reset fault should drop the connection", ProxyFaultReset},
+		{"close fault should drop the connection", ProxyFaultClose},
+		{"truncate fault should cut the response short", ProxyFaultTruncate},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newProxyTestServer(t, ProxyRule{Faults: []ProxyFaultRate{{Type: tt.fault, Rate: 1}}})
+
+			res, err := http.Get(server.URL + "/users") //nolint:noctx
+			if err == nil {
+				defer res.Body.Close()
+				_, err = io.ReadAll(res.Body)
+			}
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestProxyMiddleware_TruncateUnknownLength(t *testing.T) {
+	t.Parallel()
+
+	server := newProxyTestServer(t, ProxyRule{Faults: []ProxyFaultRate{{Type: ProxyFaultTruncate, Rate: 1}}})
+
+	res, err := http.Get(server.URL + "/chunked") //nolint:noctx
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, int64(-1), res.ContentLength)
+
+	body, err := io.ReadAll(res.Body)
+	assert.Error(t, err)
+	assert.Len(t, body, ProxyTruncatedUnknownLength)
+}
+
+func TestProxyMiddleware_Body(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("a", MatchedBodyLimit) + "stubbed"
+
+	tests := []struct {
+		name       string
+		rule       ProxyRule
+		wantStatus int
+	}{
+		{
+			name:       "rules without body condition should forward the whole body",
+			rule:       ProxyRule{Request: RequestMatcher{Path: "/echo"}, Latency: Latency{LowerBound: time.Millisecond}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rules should only match the beginning of the body, and forward the whole body",
+			rule:       ProxyRule{Request: RequestMatcher{BodyContains: "stubbed"}, StatusOverrides: []ProxyStatusOverride{{Status: http.StatusServiceUnavailable, Rate: 1}}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rules matching the beginning of the body should apply",
+			rule:       ProxyRule{Request: RequestMatcher{BodyContains: "aaa"}, StatusOverrides: []ProxyStatusOverride{{Status: http.StatusServiceUnavailable, Rate: 1}}},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newProxyTestServer(t, tt.rule)
+
+			res, err := http.Post(server.URL+"/echo", echo.MIMETextPlain, strings.NewReader(body)) //nolint:noctx
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			got, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, body, string(got))
+			}
+		})
+	}
+}
+
+func TestProxyMiddleware_Bandwidth(t *testing.T) {
+	t.Parallel()
+
+	server := newProxyTestServer(t, ProxyRule{Bandwidth: 4 * 1024})
+
+	start := time.Now()
+	res, err := http.Get(server.URL + "/users") //nolint:noctx
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Len(t, body, 1024)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond, "1kb at 4kb/s should take about 200ms")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyHeaderTimeout is how long a connection accepted by a PROXY protocol
+// listener has to send its header.
+const ProxyHeaderTimeout = 5 * time.Second
+
+// ErrInvalidProxyHeader is returned when a connection does not start with a
+// valid PROXY protocol header.
+var ErrInvalidProxyHeader = errors.New("invalid PROXY protocol header")
+
+// proxyV2Signature is the signature version 2 PROXY protocol headers start with.
+const proxyV2Signature = "\r\n\r\n\x00\r\nQUIT\n"
+
+// proxyV1MaxLength is the maximum length of a version 1 PROXY protocol header.
+const proxyV1MaxLength = 107
+
+// ReadProxyHeader reads a PROXY protocol header, in either version 1 or 2,
+// and returns the source address it holds.
+//
+// It returns a nil address for the headers which do not hold one, such as
+// the ones of the health checks sent by the proxy itself.
+//
+//nolint:nilnil
+func ReadProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	signature, err := r.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+
+	switch {
+	case string(signature) == proxyV2Signature:
+		return readProxyHeaderV2(r)
+	case bytes.HasPrefix(signature, []byte("PROXY ")):
+		return readProxyHeaderV1(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown signature", ErrInvalidProxyHeader)
+	}
+}
+
+// readProxyHeaderV1 reads a version 1, human-readable, PROXY protocol header,
+// such as "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n".
+//
+//nolint:nilnil
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= proxyV1MaxLength {
+			return nil, fmt.Errorf("%w: header too long", ErrInvalidProxyHeader)
+		}
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("%w: malformed version 1 header", ErrInvalidProxyHeader)
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("%w: malformed source address", ErrInvalidProxyHeader)
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyHeaderV2 reads a version 2, binary, PROXY protocol header.
+//
+//nolint:nilnil
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+
+	versionCommand, family := header[12], header[13]
+	if versionCommand>>4 != 2 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidProxyHeader, versionCommand>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+
+	// LOCAL commands, sent by the proxy on its own behalf, hold no address
+	const commandProxy = 0x1
+	if versionCommand&0xF != commandProxy {
+		return nil, nil
+	}
+
+	// The payload starts with the source and destination addresses, followed
+	// by the source and destination ports, and by TLVs which are ignored.
+	var size int
+	switch family >> 4 {
+	case 0x1:
+		size = net.IPv4len
+	case 0x2:
+		size = net.IPv6len
+	default:
+		// Unix and unspecified addresses are not reported
+		return nil, nil
+	}
+
+	if len(payload) < 2*size+4 {
+		return nil, fmt.Errorf("%w: truncated addresses", ErrInvalidProxyHeader)
+	}
+
+	ip := net.IP(payload[:size])
+	port := int(binary.BigEndian.Uint16(payload[2*size:]))
+
+	if family&0xF == 0x2 {
+		return &net.UDPAddr{IP: ip, Port: port}, nil
+	}
+
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// proxyProtocolListener is a net.Listener whose connections start with a
+// PROXY protocol header, and report the source address it holds as their
+// remote address.
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyProtocolConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn is a connection starting with a PROXY protocol header.
+//
+// The header is read on the first call to Read or RemoteAddr, rather than
+// when the connection is accepted, so that slow clients do not hold up the
+// listener.
+type proxyProtocolConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+	once   sync.Once
+	source net.Addr
+	err    error
+}
+
+// readHeader reads the connection's PROXY protocol header, once, and closes
+// the connection if it is invalid.
+func (c *proxyProtocolConn) readHeader() {
+	c.once.Do(func() {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(ProxyHeaderTimeout))
+		c.source, c.err = ReadProxyHeader(c.reader)
+		_ = c.Conn.SetReadDeadline(time.Time{})
+
+		if c.err != nil {
+			_ = c.Conn.Close()
+		}
+	})
+}
+
+// Read reads data from the connection, past its PROXY protocol header.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+// NetConn returns the underlying connection.
+func (c *proxyProtocolConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// RemoteAddr returns the source address of the PROXY protocol header, or
+// the connection's remote address if the header does not hold one.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.source == nil {
+		return c.Conn.RemoteAddr()
+	}
+
+	return c.source
+}
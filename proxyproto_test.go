@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proxyHeaderV2 returns a version 2 PROXY protocol header, with the given
+// command and family, holding the addresses and ports.
+func proxyHeaderV2(command, family byte, src, dst net.IP, srcPort, dstPort uint16) string {
+	payload := append(append([]byte{}, src...), dst...)
+	payload = binary.BigEndian.AppendUint16(payload, srcPort)
+	payload = binary.BigEndian.AppendUint16(payload, dstPort)
+
+	header := append([]byte(proxyV2Signature), 0x20|command, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+
+	return string(append(header, payload...))
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "version 1 TCP4 header should be parsed",
+			header: "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n",
+			want:   "192.0.2.1:56324",
+		},
+		{
+			name:   "version 1 TCP6 header should be parsed",
+			header: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n",
+			want:   "[2001:db8::1]:56324",
+		},
+		{
+			name:   "version 1 UNKNOWN header should hold no address",
+			header: "PROXY UNKNOWN\r\n",
+		},
+		{
+			name:   "version 2 IPv4 header should be parsed",
+			header: proxyHeaderV2(0x1, 0x11, net.IPv4(192, 0, 2, 1).To4(), net.IPv4(192, 0, 2, 2).To4(), 56324, 443),
+			want:   "192.0.2.1:56324",
+		},
+		{
+			name:   "version 2 IPv6 header should be parsed",
+			header: proxyHeaderV2(0x1, 0x21, net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"), 56324, 443),
+			want:   "[2001:db8::1]:56324",
+		},
+		{
+			name:   "version 2 LOCAL header should hold no address",
+			header: proxyHeaderV2(0x0, 0x11, net.IPv4(192, 0, 2, 1).To4(), net.IPv4(192, 0, 2, 2).To4(), 56324, 443),
+		},
+		{
+			name:    "truncated version 2 addresses should fail",
+			header:  proxyHeaderV2(0x1, 0x21, net.IPv4(192, 0, 2, 1).To4(), net.IPv4(192, 0, 2, 2).To4(), 56324, 443),
+			wantErr: true,
+		},
+		{
+			name:    "malformed version 1 header should fail",
+			header:  "PROXY TCP4 192.0.2.1\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "overlong version 1 header should fail",
+			header:  "PROXY TCP4 " + strings.Repeat("1", proxyV1MaxLength) + "\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing header should fail",
+			header:  "GET / HTTP/1.1\r\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := bufio.NewReader(strings.NewReader(tt.header + "GET / HTTP/1.1\r\n"))
+			got, err := ReadProxyHeader(reader)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidProxyHeader)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, got)
+			} else {
+				require.NotNil(t, got)
+				assert.Equal(t, tt.want, got.String())
+			}
+
+			rest, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, "GET / HTTP/1.1\r\n", string(rest), "data past the header should be left unread")
+		})
+	}
+}
+
+func TestListener_ProxyProtocol(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.GET("/addr", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, ctx.Request().RemoteAddr)
+	})
+
+	listener, err := NewListener(ListenerOptions{Network: "tcp", Addr: "127.0.0.1:0", ProxyProtocol: true}, e.Server)
+	require.NoError(t, err)
+	serveListener(t, listener)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, dialErr := (&net.Dialer{}).DialContext(ctx, network, addr)
+			if dialErr != nil {
+				return nil, dialErr
+			}
+
+			_, dialErr = io.WriteString(conn, "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n")
+			return conn, dialErr
+		},
+	}}
+	res, err := client.Get("http://" + listener.Addr().String() + "/addr") //nolint:noctx
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1:56324", string(body))
+
+	// Connections without a header should be closed without a response
+	res, err = http.Get("http://" + listener.Addr().String() + "/addr") //nolint:noctx
+	if err == nil {
+		res.Body.Close()
+	}
+	assert.Error(t, err)
+}
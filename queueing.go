@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// QueueingOptions describes the server as a queueing system, in the M/M/c
+// style: a number of workers, each serving requests at a given rate, and a
+// queue holding the requests which arrive while every worker is busy.
+type QueueingOptions struct {
+	// Workers is the number of requests served in parallel. Zero disables
+	// the queueing model.
+	Workers int
+
+	// ServiceRate is the mean number of requests a single worker serves per
+	// second. Service times are exponentially distributed around its inverse.
+	ServiceRate float64
+}
+
+// Enabled returns true if the queueing model applies.
+func (o QueueingOptions) Enabled() bool {
+	return o.Workers > 0
+}
+
+// Validate checks if the QueueingOptions struct satisfies the defined constraints.
+func (o QueueingOptions) Validate() error {
+	if o.Workers < 0 {
+		return errors.New("workers cannot be negative")
+	}
+
+	if o.Workers > 0 && o.ServiceRate <= 0 {
+		return errors.New("service rate must be positive")
+	}
+
+	return nil
+}
+
+// QueueingModel derives the response time of requests from the number of
+// requests in flight, so that latency rises as the load approaches, and
+// exceeds, the capacity of the modelled server.
+//
+// It is safe for concurrent use.
+type QueueingModel struct {
+	options  QueueingOptions
+	inFlight atomic.Int64
+}
+
+// NewQueueingModel creates a new QueueingModel instance.
+func NewQueueingModel(options QueueingOptions) *QueueingModel {
+	return &QueueingModel{options: options}
+}
+
+// InFlight returns the number of requests currently in flight.
+func (m *QueueingModel) InFlight() int64 {
+	return m.inFlight.Load()
+}
+
+// Delay returns a response time for a request arriving while inFlight
+// requests, itself included, are in flight.
+//
+// Requests arriving while a worker is free are only subject to their
+// service time. The others wait, on top of it, for as many requests as are
+// queued ahead of them to be served, by any of the workers.
+//
+//nolint:gosec
+func (m *QueueingModel) Delay(inFlight int64) time.Duration {
+	workers := int64(m.options.Workers)
+	rate := m.options.ServiceRate
+
+	// Service time, exponentially distributed with a mean of 1/rate
+	seconds := rand.ExpFloat64() / rate
+
+	// Queueing time: workers free up at a combined rate of workers*rate,
+	// and the request waits for one departure per request ahead of it.
+	for queued := inFlight - workers; queued > 0; queued-- {
+		seconds += rand.ExpFloat64() / (float64(workers) * rate)
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// QueueingMiddleware returns a middleware delaying every request whose path
+// does not start with one of the skipped prefixes by the response time the
+// queueing model derives from the current load.
+func QueueingMiddleware(model *QueueingModel, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if hasAnyPathPrefix(ctx.Request().URL.Path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			inFlight := model.inFlight.Add(1)
+			defer model.inFlight.Add(-1)
+
+			time.Sleep(model.Delay(inFlight))
+
+			return next(ctx)
+		}
+	}
+}
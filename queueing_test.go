@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueingOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options QueueingOptions
+		wantErr bool
+	}{
+		{"disabled model should be valid", QueueingOptions{}, false},
+		{"enabled model should be valid", QueueingOptions{Workers: 4, ServiceRate: 10}, false},
+		{"negative workers should fail", QueueingOptions{Workers: -1}, true},
+		{"missing service rate should fail", QueueingOptions{Workers: 4}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantErr, tt.options.Validate() != nil)
+		})
+	}
+}
+
+func TestQueueingModel_Delay(t *testing.T) {
+	t.Parallel()
+
+	model := NewQueueingModel(QueueingOptions{Workers: 4, ServiceRate: 100})
+
+	// mean returns the mean delay over many samples
+	mean := func(inFlight int64) time.Duration {
+		const samples = 20000
+
+		var total time.Duration
+		for i := 0; i < samples; i++ {
+			total += model.Delay(inFlight)
+		}
+
+		return total / samples
+	}
+
+	tests := []struct {
+		name     string
+		inFlight int64
+		want     time.Duration
+	}{
+		{"idle server should only apply the service time", 1, 10 * time.Millisecond},
+		{"busy workers without queue should only apply the service time", 4, 10 * time.Millisecond},
+		{"queued requests should wait for the ones ahead of them", 12, 10*time.Millisecond + 8*2500*time.Microsecond},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.InEpsilon(t, float64(tt.want), float64(mean(tt.inFlight)), 0.05)
+		})
+	}
+}
+
+func TestQueueingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	model := NewQueueingModel(QueueingOptions{Workers: 1, ServiceRate: 1000})
+
+	e := echo.New()
+	e.Use(QueueingMiddleware(model, DefaultAdminPrefix))
+
+	release := make(chan struct{})
+	e.GET("/slow", func(ctx echo.Context) error {
+		<-release
+		return ctx.NoContent(http.StatusOK)
+	})
+	e.GET(DefaultAdminPrefix+"/settings", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return model.InFlight() == 3 }, time.Second, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultAdminPrefix+"/settings", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(3), model.InFlight(), "skipped requests should not be counted")
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(0), model.InFlight())
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderRateLimitLimit is the response header holding the request quota.
+	HeaderRateLimitLimit = "RateLimit-Limit"
+
+	// HeaderRateLimitRemaining is the response header holding the remaining quota.
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+
+	// HeaderRateLimitReset is the response header holding the number of
+	// seconds until the quota resets.
+	HeaderRateLimitReset = "RateLimit-Reset"
+)
+
+// RateLimitAlgorithm is the algorithm used to enforce a rate limit.
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket allows bursts of up to limit requests, and refills
+	// the bucket continuously at a rate of limit requests per window.
+	TokenBucket RateLimitAlgorithm = "token-bucket"
+
+	// SlidingWindow allows up to limit requests over any window.
+	SlidingWindow RateLimitAlgorithm = "sliding-window"
+)
+
+// ErrInvalidRateLimit is returned when a rate limit option is invalid.
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// RateLimitOptions describes a rate limit.
+type RateLimitOptions struct {
+	// Limit is the number of requests allowed per window.
+	Limit int
+
+	// Window is the duration over which the limit applies.
+	Window time.Duration
+
+	// Algorithm is the algorithm used to enforce the limit.
+	Algorithm RateLimitAlgorithm
+
+	// Key identifies the clients the limit applies to independently. It is
+	// either "ip", "apikey", or "header:<name>".
+	Key string
+}
+
+// DefaultRateLimitOptions returns the rate limit options used when none are specified.
+func DefaultRateLimitOptions() RateLimitOptions {
+	return RateLimitOptions{
+		Limit:     10,
+		Window:    time.Second,
+		Algorithm: TokenBucket,
+		Key:       "ip",
+	}
+}
+
+// Validate checks if the RateLimitOptions struct satisfies the defined constraints.
+func (o RateLimitOptions) Validate() error {
+	if o.Limit <= 0 {
+		return fmt.Errorf("%w: limit must be positive", ErrInvalidRateLimit)
+	}
+
+	if o.Window <= 0 {
+		return fmt.Errorf("%w: window must be positive", ErrInvalidRateLimit)
+	}
+
+	if o.Algorithm != TokenBucket && o.Algorithm != SlidingWindow {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidRateLimit, o.Algorithm)
+	}
+
+	if o.Key != "ip" && o.Key != "apikey" && !strings.HasPrefix(o.Key, "header:") {
+		return fmt.Errorf("%w: unsupported key %q", ErrInvalidRateLimit, o.Key)
+	}
+
+	return nil
+}
+
+// clientKey returns the key identifying the client that sent the request.
+func (o RateLimitOptions) clientKey(ctx echo.Context) string {
+	switch {
+	case o.Key == "apikey":
+		if key := ctx.Request().Header.Get(DefaultAPIKeyHeader); key != "" {
+			return key
+		}
+
+		return ctx.QueryParam(DefaultAPIKeyQuery)
+	case strings.HasPrefix(o.Key, "header:"):
+		return ctx.Request().Header.Get(strings.TrimPrefix(o.Key, "header:"))
+	default:
+		return ctx.RealIP()
+	}
+}
+
+// rateLimiter decides whether a request is allowed.
+//
+// It returns whether the request is allowed, the remaining quota, and how
+// long until the quota resets. When the request is not allowed, retryAfter
+// holds how long until the next request would be.
+type rateLimiter interface {
+	allow(now time.Time) (allowed bool, remaining int, reset time.Duration, retryAfter time.Duration)
+}
+
+// tokenBucket implements the token bucket algorithm.
+type tokenBucket struct {
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+}
+
+func (b *tokenBucket) allow(now time.Time) (bool, int, time.Duration, time.Duration) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	reset := secondsToDuration((b.capacity - b.tokens) / b.rate)
+	retryAfter := secondsToDuration((1 - b.tokens) / b.rate)
+
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+// slidingWindow implements the sliding window log algorithm.
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+	log    []time.Time
+}
+
+func (w *slidingWindow) allow(now time.Time) (bool, int, time.Duration, time.Duration) {
+	// Forget the requests that fell out of the window
+	i := 0
+	for i < len(w.log) && now.Sub(w.log[i]) >= w.window {
+		i++
+	}
+	w.log = w.log[i:]
+
+	allowed := len(w.log) < w.limit
+	if allowed {
+		w.log = append(w.log, now)
+	}
+
+	reset := w.log[0].Add(w.window).Sub(now)
+
+	return allowed, w.limit - len(w.log), reset, reset
+}
+
+// rateLimitersSweepInterval is how often, at most, idle rate limiters are evicted.
+const rateLimitersSweepInterval = time.Second
+
+// rateLimiterEntry is a rate limiter, along with when it was last used.
+type rateLimiterEntry struct {
+	limiter  rateLimiter
+	window   time.Duration
+	lastSeen time.Time
+}
+
+// RateLimiters holds the state of the rate limiters, per bucket, options and client.
+//
+// Rate limiters left idle for a full window are back to their initial state,
+// and are evicted, so that the number of rate limiters held is bounded by
+// the number of clients seen over the last window.
+type RateLimiters struct {
+	mu        sync.Mutex
+	limiters  map[string]*rateLimiterEntry
+	lastSweep time.Time
+}
+
+// NewRateLimiters creates a new, empty, RateLimiters instance.
+func NewRateLimiters() *RateLimiters {
+	return &RateLimiters{limiters: make(map[string]*rateLimiterEntry)}
+}
+
+// Allow decides whether a request from the client should be allowed.
+func (r *RateLimiters) Allow(
+	bucket string,
+	client string,
+	options RateLimitOptions,
+	now time.Time,
+) (allowed bool, remaining int, reset time.Duration, retryAfter time.Duration) {
+	key := fmt.Sprintf("%s|%s|%d|%s|%s", bucket, options.Algorithm, options.Limit, options.Window, client)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep(now)
+
+	entry, ok := r.limiters[key]
+	if !ok {
+		entry = &rateLimiterEntry{window: options.Window}
+		if options.Algorithm == SlidingWindow {
+			entry.limiter = &slidingWindow{limit: options.Limit, window: options.Window}
+		} else {
+			entry.limiter = &tokenBucket{
+				capacity: float64(options.Limit),
+				rate:     float64(options.Limit) / options.Window.Seconds(),
+				tokens:   float64(options.Limit),
+				last:     now,
+			}
+		}
+
+		r.limiters[key] = entry
+	}
+	entry.lastSeen = now
+
+	return entry.limiter.allow(now)
+}
+
+// sweep evicts the rate limiters left idle for a full window, at most once
+// per rateLimitersSweepInterval. The caller must hold the lock.
+func (r *RateLimiters) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < rateLimitersSweepInterval {
+		return
+	}
+	r.lastSweep = now
+
+	for key, entry := range r.limiters {
+		if now.Sub(entry.lastSeen) >= entry.window {
+			delete(r.limiters, key)
+		}
+	}
+}
+
+// GetRatelimitBucket is a handler enforcing a rate limit.
+//
+// Requests are counted per {bucket} and per client, the client being
+// identified according to the key option. The options default to the
+// ones the server was started with, and can be overridden per request.
+//
+// Allowed requests receive a 200, and limited ones a 429 with a Retry-After
+// header. Both carry the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers.
+func (s *ServerImpl) GetRatelimitBucket(ctx echo.Context, bucket string, params GetRatelimitBucketParams) error {
+	options := s.rateLimit
+	if params.Limit != nil {
+		options.Limit = *params.Limit
+	}
+
+	if params.Window != nil {
+		window, err := time.ParseDuration(*params.Window)
+		if err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed parsing window: %s", err))
+		}
+
+		options.Window = window
+	}
+
+	if params.Algorithm != nil {
+		options.Algorithm = RateLimitAlgorithm(*params.Algorithm)
+	}
+
+	if params.Key != nil {
+		options.Key = *params.Key
+	}
+
+	if err := options.Validate(); err != nil {
+		slog.Error(
+			"failed validating rate limit",
+			"handler", "GetRatelimitBucket",
+			"bucket", bucket,
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	client := options.clientKey(ctx)
+	allowed, remaining, reset, retryAfter := s.rateLimiters.Allow(bucket, client, options, time.Now())
+
+	s.metrics.Set("ratelimit."+bucket+".limit", int64(options.Limit))
+	s.metrics.Set("ratelimit."+bucket+".window_ms", options.Window.Milliseconds())
+
+	header := ctx.Response().Header()
+	header.Set(HeaderRateLimitLimit, strconv.Itoa(options.Limit))
+	header.Set(HeaderRateLimitRemaining, strconv.Itoa(remaining))
+	header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(reset)))
+
+	response := RateLimitStatus{
+		Bucket:    bucket,
+		Client:    client,
+		Limit:     options.Limit,
+		Remaining: remaining,
+		Reset:     ceilSeconds(reset),
+	}
+
+	if !allowed {
+		s.metrics.Add("ratelimit."+bucket+".limited", 1)
+		header.Set(echo.HeaderRetryAfter, strconv.Itoa(max(1, ceilSeconds(retryAfter))))
+
+		return ctx.JSON(http.StatusTooManyRequests, response)
+	}
+
+	s.metrics.Add("ratelimit."+bucket+".allowed", 1)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ceilSeconds returns the duration as a whole number of seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// secondsToDuration converts a number of seconds to a duration.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiters_Allow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		algorithm RateLimitAlgorithm
+	}{
+		{"token bucket", TokenBucket},
+		{"sliding window", SlidingWindow},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiters := NewRateLimiters()
+			options := RateLimitOptions{Limit: 2, Window: time.Second, Algorithm: tt.algorithm, Key: "ip"}
+			now := time.Now()
+
+			allowed, remaining, _, _ := limiters.Allow("bucket", "client", options, now)
+			assert.True(t, allowed)
+			assert.Equal(t, 1, remaining)
+
+			allowed, remaining, _, _ = limiters.Allow("bucket", "client", options, now)
+			assert.True(t, allowed)
+			assert.Equal(t, 0, remaining)
+
+			allowed, _, _, retryAfter := limiters.Allow("bucket", "client", options, now)
+			assert.False(t, allowed)
+			assert.Greater(t, retryAfter, time.Duration(0))
+
+			// Other clients and buckets are counted independently
+			allowed, _, _, _ = limiters.Allow("bucket", "other", options, now)
+			assert.True(t, allowed)
+			allowed, _, _, _ = limiters.Allow("other", "client", options, now)
+			assert.True(t, allowed)
+
+			// The quota is restored once the window has elapsed
+			allowed, _, _, _ = limiters.Allow("bucket", "client", options, now.Add(time.Second))
+			assert.True(t, allowed)
+		})
+	}
+}
+
+func TestRateLimiters_Evict(t *testing.T) {
+	t.Parallel()
+
+	limiters := NewRateLimiters()
+	options := RateLimitOptions{Limit: 1, Window: time.Second, Algorithm: TokenBucket, Key: "ip"}
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		limiters.Allow("bucket", strconv.Itoa(i), options, now)
+	}
+	assert.Len(t, limiters.limiters, 100)
+
+	// Limiters idle for a full window are evicted, while active ones are kept
+	allowed, _, _, _ := limiters.Allow("bucket", "0", options, now.Add(time.Second))
+	assert.True(t, allowed)
+	assert.Len(t, limiters.limiters, 1)
+
+	allowed, _, _, _ = limiters.Allow("bucket", "0", options, now.Add(time.Second))
+	assert.False(t, allowed, "active limiters should keep their state")
+}
+
+func TestGetRatelimitBucket(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	server := NewServerImpl(Config{RateLimit: DefaultRateLimitOptions()})
+	RegisterHandlers(e, server)
+
+	wantStatuses := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, wantStatus := range wantStatuses {
+		req := httptest.NewRequest(http.MethodGet, "/ratelimit/test?limit=2&window=1m&key=header:X-Tenant", nil)
+		req.Header.Set("X-Tenant", "a")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, wantStatus, rec.Code, "request %d", i)
+		assert.Equal(t, "2", rec.Header().Get(HeaderRateLimitLimit))
+		assert.NotEmpty(t, rec.Header().Get(HeaderRateLimitRemaining))
+		assert.NotEmpty(t, rec.Header().Get(HeaderRateLimitReset))
+
+		if wantStatus == http.StatusTooManyRequests {
+			assert.Equal(t, "30", rec.Header().Get(echo.HeaderRetryAfter))
+		}
+	}
+
+	allowed, _ := server.metrics.Get("ratelimit.test.allowed")
+	limited, _ := server.metrics.Get("ratelimit.test.limited")
+	limit, _ := server.metrics.Get("ratelimit.test.limit")
+	assert.Equal(t, int64(2), allowed)
+	assert.Equal(t, int64(1), limited)
+	assert.Equal(t, int64(2), limit)
+}
+
+func TestGetRatelimitBucketInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"zero limit should fail", "limit=0"},
+		{"invalid window should fail", "window=invalid"},
+		{"unsupported algorithm should fail", "algorithm=leaky-bucket"},
+		{"unsupported key should fail", "key=cookie"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			RegisterHandlers(e, NewServerImpl(Config{RateLimit: DefaultRateLimitOptions()}))
+			req := httptest.NewRequest(http.MethodGet, "/ratelimit/test?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetRedirectHops is a handler issuing a chain of {hops} redirects for GET requests.
+func (s *ServerImpl) GetRedirectHops(ctx echo.Context, hops RedirectHops, params GetRedirectHopsParams) error {
+	return s.redirect(ctx, hops, params)
+}
+
+// PostRedirectHops is a handler issuing a chain of {hops} redirects for POST requests.
+func (s *ServerImpl) PostRedirectHops(ctx echo.Context, hops RedirectHops, params PostRedirectHopsParams) error {
+	return s.redirect(ctx, hops, GetRedirectHopsParams(params))
+}
+
+// PutRedirectHops is a handler issuing a chain of {hops} redirects for PUT requests.
+func (s *ServerImpl) PutRedirectHops(ctx echo.Context, hops RedirectHops, params PutRedirectHopsParams) error {
+	return s.redirect(ctx, hops, GetRedirectHopsParams(params))
+}
+
+// PatchRedirectHops is a handler issuing a chain of {hops} redirects for PATCH requests.
+func (s *ServerImpl) PatchRedirectHops(ctx echo.Context, hops RedirectHops, params PatchRedirectHopsParams) error {
+	return s.redirect(ctx, hops, GetRedirectHopsParams(params))
+}
+
+// DeleteRedirectHops is a handler issuing a chain of {hops} redirects for DELETE requests.
+func (s *ServerImpl) DeleteRedirectHops(ctx echo.Context, hops RedirectHops, params DeleteRedirectHopsParams) error {
+	return s.redirect(ctx, hops, GetRedirectHopsParams(params))
+}
+
+// redirect issues the next redirect of the chain, or responds as the final
+// destination once no hops are left.
+//
+// Each redirect points to /redirect/{hops-1}, and carries the count of
+// redirects followed so far in the "redirects" query parameter. When the loop
+// parameter is set, each redirect points back to /redirect/{hops} instead.
+//
+// The final destination responds with a JSON object reporting the count of
+// redirects followed, and the method and body of the request that reached it.
+func (s *ServerImpl) redirect(ctx echo.Context, hops int, params GetRedirectHopsParams) error {
+	if hops < 0 {
+		return ctx.String(http.StatusBadRequest, "hops cannot be negative")
+	}
+
+	status := http.StatusFound
+	if params.Status != nil {
+		status = *params.Status
+	}
+
+	if !isRedirectStatus(status) {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("unsupported redirect status %d", status))
+	}
+
+	redirects := 0
+	if params.Redirects != nil {
+		redirects = *params.Redirects
+	}
+
+	loop := params.Loop != nil && *params.Loop
+	if hops == 0 && !loop {
+		return s.redirectDestination(ctx, redirects)
+	}
+
+	next := hops - 1
+	if loop {
+		next = hops
+	}
+
+	// Carry the parameters along the chain
+	query := url.Values{}
+	query.Set("status", strconv.Itoa(status))
+	query.Set("redirects", strconv.Itoa(redirects+1))
+
+	if loop {
+		query.Set("loop", "true")
+	}
+
+	location := url.URL{Path: fmt.Sprintf("/redirect/%d", next)}
+
+	switch {
+	case params.Host != nil && *params.Host != "":
+		// Cross-host redirects are necessarily absolute
+		location.Scheme = ctx.Scheme()
+		location.Host = *params.Host
+		query.Set("host", *params.Host)
+	case params.Absolute != nil && *params.Absolute:
+		location.Scheme = ctx.Scheme()
+		location.Host = ctx.Request().Host
+		query.Set("absolute", "true")
+	}
+
+	location.RawQuery = query.Encode()
+
+	return ctx.Redirect(status, location.String())
+}
+
+// redirectDestination responds as the final destination of a redirect chain.
+func (s *ServerImpl) redirectDestination(ctx echo.Context, redirects int) error {
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		slog.Error(
+			"failed reading request body",
+			"handler", "redirect",
+			"error_message", err.Error(),
+		)
+
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	method := ctx.Request().Method
+	bodyString := string(body)
+	contentType := ctx.Request().Header.Get(echo.HeaderContentType)
+
+	return ctx.JSON(http.StatusOK, RedirectDestination{
+		Redirects:   &redirects,
+		Method:      &method,
+		Body:        &bodyString,
+		ContentType: &contentType,
+	})
+}
+
+// isRedirectStatus returns true if the status is one of the supported redirect statuses.
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
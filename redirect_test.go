@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		hops         int
+		status       int
+		absolute     bool
+		loop         bool
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "default status should be 302",
+			hops:         3,
+			wantStatus:   http.StatusFound,
+			wantLocation: "/redirect/2?redirects=1&status=302",
+		},
+		{
+			name:         "custom status should be used",
+			hops:         1,
+			status:       http.StatusPermanentRedirect,
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "/redirect/0?redirects=1&status=308",
+		},
+		{
+			name:         "absolute location should include the host",
+			hops:         2,
+			absolute:     true,
+			wantStatus:   http.StatusFound,
+			wantLocation: "http://example.com/redirect/1?absolute=true&redirects=1&status=302",
+		},
+		{
+			name:         "loop should redirect to itself",
+			hops:         2,
+			loop:         true,
+			wantStatus:   http.StatusFound,
+			wantLocation: "/redirect/2?loop=true&redirects=1&status=302",
+		},
+		{
+			name:       "zero hops should reach the destination",
+			hops:       0,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "negative hops should fail",
+			hops:       -1,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported status should fail",
+			hops:       1,
+			status:     http.StatusOK,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/redirect", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			handler := &ServerImpl{}
+
+			params := GetRedirectHopsParams{}
+			if tt.status != 0 {
+				params.Status = &tt.status
+			}
+			if tt.absolute {
+				params.Absolute = &tt.absolute
+			}
+			if tt.loop {
+				params.Loop = &tt.loop
+			}
+
+			assert.NoError(t, handler.GetRedirectHops(c, tt.hops, params))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantLocation, rec.Header().Get(echo.HeaderLocation))
+		})
+	}
+}
+
+func TestRedirectChain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		status     string
+		wantMethod string
+		wantBody   string
+	}{
+		{
+			name:       "307 redirects should preserve the method and body",
+			method:     http.MethodPost,
+			status:     "307",
+			wantMethod: http.MethodPost,
+			wantBody:   "payload",
+		},
+		{
+			name:       "303 redirects should switch to GET and drop the body",
+			method:     http.MethodPost,
+			status:     "303",
+			wantMethod: http.MethodGet,
+			wantBody:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			RegisterHandlers(e, &ServerImpl{})
+			server := httptest.NewServer(e)
+			defer server.Close()
+
+			req, err := http.NewRequestWithContext(
+				context.Background(),
+				tt.method,
+				server.URL+"/redirect/3?status="+tt.status,
+				strings.NewReader("payload"),
+			)
+			require.NoError(t, err)
+
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var destination RedirectDestination
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&destination))
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, 3, *destination.Redirects)
+			assert.Equal(t, tt.wantMethod, *destination.Method)
+			assert.Equal(t, tt.wantBody, *destination.Body)
+		})
+	}
+}
+
+func TestRedirectLoop(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, &ServerImpl{})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/redirect/1?loop=true", nil)
+	require.NoError(t, err)
+
+	resp, err := server.Client().Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stopped after 10 redirects")
+}
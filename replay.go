@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReplayMatch describes how strictly requests must match a recording to be
+// answered with it.
+type ReplayMatch string
+
+const (
+	// ReplayMatchPath matches requests on their method and path.
+	ReplayMatchPath ReplayMatch = "path"
+
+	// ReplayMatchQuery matches requests on their method, path and query parameters.
+	ReplayMatchQuery ReplayMatch = "query"
+
+	// ReplayMatchBody matches requests on their method, path, query parameters and body.
+	ReplayMatchBody ReplayMatch = "body"
+)
+
+// ReplayFallbackRoutes is the replay fallback handing unmatched requests over
+// to the server's routes.
+const ReplayFallbackRoutes = "routes"
+
+// ReplayOptions holds the options of the replay mode.
+type ReplayOptions struct {
+	// File is the path of the HAR file to replay. Empty disables the replay mode.
+	File string
+
+	// Timings replays the recorded duration of each response as latency.
+	Timings bool
+
+	// Match is how strictly requests must match a recording.
+	Match ReplayMatch
+
+	// Fallback is how unmatched requests are answered: either handed over
+	// to the server's routes, with "routes", or with the provided status.
+	Fallback string
+}
+
+// DefaultReplayOptions returns the default replay options.
+func DefaultReplayOptions() ReplayOptions {
+	return ReplayOptions{
+		Match:    ReplayMatchQuery,
+		Fallback: ReplayFallbackRoutes,
+	}
+}
+
+// Validate checks if the ReplayOptions struct satisfies the defined constraints.
+func (o ReplayOptions) Validate() error {
+	switch o.Match {
+	case ReplayMatchPath, ReplayMatchQuery, ReplayMatchBody:
+	default:
+		return fmt.Errorf("unsupported match %q", o.Match)
+	}
+
+	if o.Fallback != ReplayFallbackRoutes {
+		status, err := strconv.Atoi(o.Fallback)
+		if err != nil || status < 100 || status > 599 {
+			return fmt.Errorf("fallback must either be %q or a status, got %q", ReplayFallbackRoutes, o.Fallback)
+		}
+	}
+
+	return nil
+}
+
+// ParseHAR parses an HTTP Archive.
+func ParseHAR(r io.Reader) (HAR, error) {
+	var har HAR
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return har, fmt.Errorf("failed parsing HAR: %w", err)
+	}
+
+	return har, nil
+}
+
+// Replayer answers requests with the responses recorded in an HTTP Archive.
+//
+// Requests matching several recordings are answered with each of them in
+// turn, in the order they were recorded.
+//
+// It is safe for concurrent use.
+type Replayer struct {
+	options ReplayOptions
+
+	mu         sync.Mutex
+	recordings map[string][]HAREntry
+	next       map[string]int
+}
+
+// NewReplayer creates a new Replayer instance, replaying the entries of the HTTP Archive.
+//
+// Entries whose response has no valid status, such as the ones browsers
+// export for blocked or aborted requests, are skipped with a warning.
+func NewReplayer(har HAR, options ReplayOptions) (*Replayer, error) {
+	replayer := &Replayer{
+		options:    options,
+		recordings: make(map[string][]HAREntry),
+		next:       make(map[string]int),
+	}
+
+	for i, entry := range har.Log.Entries {
+		if entry.Response.Status < 100 || entry.Response.Status > 999 {
+			slog.Warn(
+				"skipping recording without a valid response status",
+				"handler", "NewReplayer",
+				"entry", i+1,
+				"url", entry.Request.URL,
+				"status", entry.Response.Status,
+			)
+
+			continue
+		}
+
+		target, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid url: %w", i+1, err)
+		}
+
+		body := ""
+		if entry.Request.PostData != nil {
+			body = entry.Request.PostData.Text
+			if entry.Request.PostData.Encoding == "base64" {
+				decoded, err := base64.StdEncoding.DecodeString(body)
+				if err != nil {
+					return nil, fmt.Errorf("entry %d: invalid base64 request body: %w", i+1, err)
+				}
+				body = string(decoded)
+			}
+		}
+
+		key := replayer.key(entry.Request.Method, target.Path, target.Query(), body)
+		replayer.recordings[key] = append(replayer.recordings[key], entry)
+	}
+
+	return replayer, nil
+}
+
+// Match returns the recording the request should be answered with, and false
+// if there is none.
+func (r *Replayer) Match(method, path string, query url.Values, body string) (HAREntry, bool) {
+	key := r.key(method, path, query, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recordings, ok := r.recordings[key]
+	if !ok {
+		return HAREntry{}, false
+	}
+
+	i := r.next[key]
+	r.next[key] = (i + 1) % len(recordings)
+
+	return recordings[i], true
+}
+
+// key returns the key identifying the recordings a request matches, given
+// the replayer's match strictness.
+func (r *Replayer) key(method, path string, query url.Values, body string) string {
+	parts := []string{strings.ToUpper(method), path}
+
+	switch r.options.Match {
+	case ReplayMatchBody:
+		parts = append(parts, query.Encode(), body)
+	case ReplayMatchQuery:
+		parts = append(parts, query.Encode())
+	case ReplayMatchPath:
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// ReplayMiddleware returns a middleware answering requests with their
+// matching recording, and applying the replayer's fallback to the others.
+// Requests whose path starts with one of the skipped prefixes are never replayed.
+func ReplayMiddleware(replayer *Replayer, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if hasAnyPathPrefix(req.URL.Path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			var body []byte
+			if replayer.options.Match == ReplayMatchBody && req.Body != nil {
+				var err error
+				if body, err = io.ReadAll(req.Body); err != nil {
+					return err
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			entry, ok := replayer.Match(req.Method, req.URL.Path, req.URL.Query(), string(body))
+			if !ok {
+				if replayer.options.Fallback == ReplayFallbackRoutes {
+					return next(ctx)
+				}
+
+				status, _ := strconv.Atoi(replayer.options.Fallback)
+				return ctx.String(status, "no recording matches the request")
+			}
+
+			if replayer.options.Timings {
+				time.Sleep(time.Duration(entry.Time * float64(time.Millisecond)))
+			}
+
+			return writeRecordedResponse(ctx, entry.Response)
+		}
+	}
+}
+
+// writeRecordedResponse writes the response recorded in an HTTP Archive.
+func writeRecordedResponse(ctx echo.Context, res HARResponse) error {
+	body := []byte(res.Content.Text)
+	if res.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(res.Content.Text)
+		if err != nil {
+			return fmt.Errorf("failed decoding recorded body: %w", err)
+		}
+		body = decoded
+	}
+
+	for _, header := range res.Headers {
+		// HTTP/2 pseudo-headers are not headers
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+
+		// The recorded body is served as is, whatever the recorded framing and encoding
+		switch http.CanonicalHeaderKey(header.Name) {
+		case echo.HeaderContentLength, echo.HeaderContentEncoding, "Transfer-Encoding", echo.HeaderConnection:
+			continue
+		}
+
+		ctx.Response().Header().Add(header.Name, header.Value)
+	}
+
+	contentType := ctx.Response().Header().Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = res.Content.MimeType
+	}
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+
+	return ctx.Blob(res.Status, contentType, body)
+}
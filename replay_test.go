@@ -0,0 +1,206 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const replayTestHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "test", "version": "1"},
+    "entries": [
+      {
+        "time": 30,
+        "request": {"method": "GET", "url": "http://example.com/users?page=1"},
+        "response": {
+          "status": 200,
+          "headers": [
+            {"name": "content-type", "value": "application/json"},
+            {"name": "set-cookie", "value": "a=1"},
+            {"name": "set-cookie", "value": "b=2"},
+            {"name": "content-length", "value": "999"}
+          ],
+          "content": {"size": 12, "mimeType": "application/json", "text": "{\"page\": 1}"}
+        }
+      },
+      {
+        "time": 0,
+        "request": {"method": "GET", "url": "http://example.com/users?page=1"},
+        "response": {
+          "status": 503,
+          "content": {"size": 5, "mimeType": "text/plain", "text": "b29wcw==", "encoding": "base64"}
+        }
+      },
+      {
+        "time": 0,
+        "request": {"method": "POST", "url": "http://example.com/users", "postData": {"mimeType": "application/json", "text": "{\"name\":\"alice\"}"}},
+        "response": {"status": 201, "content": {"size": 0, "mimeType": "text/plain"}}
+      },
+      {
+        "time": 0,
+        "request": {"method": "PUT", "url": "http://example.com/blobs/1", "postData": {"mimeType": "application/octet-stream", "text": "//4A", "encoding": "base64"}},
+        "response": {"status": 204, "content": {"size": 0, "mimeType": "text/plain"}}
+      },
+      {
+        "time": 0,
+        "request": {"method": "GET", "url": "http://example.com/aborted"},
+        "response": {"status": 0, "content": {"size": 0, "mimeType": ""}}
+      }
+    ]
+  }
+}`
+
+func TestReplayMiddleware(t *testing.T) {
+	t.Parallel()
+
+	har, err := ParseHAR(strings.NewReader(replayTestHAR))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		options     ReplayOptions
+		method      string
+		target      string
+		body        string
+		wantStatus  []int
+		wantBody    string
+		wantLatency time.Duration
+	}{
+		{
+			name:       "matching requests should be answered with each recording in turn",
+			options:    DefaultReplayOptions(),
+			method:     http.MethodGet,
+			target:     "/users?page=1",
+			wantStatus: []int{http.StatusOK, http.StatusServiceUnavailable, http.StatusOK},
+		},
+		{
+			name:       "different query should fall back to the routes",
+			options:    DefaultReplayOptions(),
+			method:     http.MethodGet,
+			target:     "/users?page=2",
+			wantStatus: []int{http.StatusTeapot},
+		},
+		{
+			name:       "path strictness should ignore the query",
+			options:    ReplayOptions{Match: ReplayMatchPath, Fallback: ReplayFallbackRoutes},
+			method:     http.MethodGet,
+			target:     "/users?page=2",
+			wantStatus: []int{http.StatusOK},
+			wantBody:   `{"page": 1}`,
+		},
+		{
+			name:       "body strictness should match the body",
+			options:    ReplayOptions{Match: ReplayMatchBody, Fallback: ReplayFallbackRoutes},
+			method:     http.MethodPost,
+			target:     "/users",
+			body:       `{"name":"alice"}`,
+			wantStatus: []int{http.StatusCreated},
+		},
+		{
+			name:       "body strictness should not match a different body",
+			options:    ReplayOptions{Match: ReplayMatchBody, Fallback: "404"},
+			method:     http.MethodPost,
+			target:     "/users",
+			body:       `{"name":"bob"}`,
+			wantStatus: []int{http.StatusNotFound},
+		},
+		{
+			name:       "body strictness should match base64-encoded bodies",
+			options:    ReplayOptions{Match: ReplayMatchBody, Fallback: "404"},
+			method:     http.MethodPut,
+			target:     "/blobs/1",
+			body:       "\xff\xfe\x00",
+			wantStatus: []int{http.StatusNoContent},
+		},
+		{
+			name:       "recordings without a valid status should be skipped",
+			options:    ReplayOptions{Match: ReplayMatchPath, Fallback: ReplayFallbackRoutes},
+			method:     http.MethodGet,
+			target:     "/aborted",
+			wantStatus: []int{http.StatusTeapot},
+		},
+		{
+			name:        "timings should be replayed as latency",
+			options:     ReplayOptions{Match: ReplayMatchQuery, Fallback: ReplayFallbackRoutes, Timings: true},
+			method:      http.MethodGet,
+			target:      "/users?page=1",
+			wantStatus:  []int{http.StatusOK},
+			wantLatency: 30 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			replayer, err := NewReplayer(har, tt.options)
+			require.NoError(t, err)
+
+			e := echo.New()
+			e.Use(ReplayMiddleware(replayer))
+			e.Any("/*", func(ctx echo.Context) error {
+				return ctx.NoContent(http.StatusTeapot)
+			})
+
+			for _, wantStatus := range tt.wantStatus {
+				req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+				rec := httptest.NewRecorder()
+
+				start := time.Now()
+				e.ServeHTTP(rec, req)
+
+				assert.Equal(t, wantStatus, rec.Code)
+				assert.GreaterOrEqual(t, time.Since(start), tt.wantLatency)
+				if tt.wantBody != "" {
+					assert.Equal(t, tt.wantBody, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteRecordedResponse(t *testing.T) {
+	t.Parallel()
+
+	har, err := ParseHAR(strings.NewReader(replayTestHAR))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		entry           HAREntry
+		wantBody        string
+		wantContentType string
+		wantCookies     []string
+	}{
+		{
+			entry:           har.Log.Entries[0],
+			wantBody:        `{"page": 1}`,
+			wantContentType: "application/json",
+			wantCookies:     []string{"a=1", "b=2"},
+		},
+		{
+			entry:           har.Log.Entries[1],
+			wantBody:        "oops",
+			wantContentType: "text/plain",
+		},
+	} {
+		rec := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		require.NoError(t, writeRecordedResponse(ctx, tt.entry.Response))
+		assert.Equal(t, tt.entry.Response.Status, rec.Code)
+		assert.Equal(t, tt.wantBody, rec.Body.String())
+		assert.Equal(t, tt.wantContentType, rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, tt.wantCookies, rec.Header().Values("Set-Cookie"))
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentLength), "recorded content length should not be replayed")
+	}
+}
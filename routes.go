@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// RoutesFile describes additional routes served by lhotse, as found in a
+// routes file.
+type RoutesFile struct {
+	Routes []RouteDefinition `json:"routes" yaml:"routes"`
+}
+
+// RouteDefinition describes an additional route, and the response it serves.
+type RouteDefinition struct {
+	// Methods are the methods the route responds to. Defaults to GET.
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+
+	// Path is the path of the route. Path parameters are expressed either
+	// as registered, such as "/users/:id", or in the OpenAPI style, such as
+	// "/users/{id}".
+	Path string `json:"path" yaml:"path"`
+
+	// Response describes the response served by the route.
+	Response ResponseDefinition `json:"response" yaml:"response"`
+}
+
+// Validate checks if the RouteDefinition struct satisfies the defined
+// constraints, and compiles its response.
+func (d *RouteDefinition) Validate() error {
+	if !strings.HasPrefix(d.Path, "/") {
+		return fmt.Errorf("path %q must start with a /", d.Path)
+	}
+
+	for _, method := range d.Methods {
+		if !isHTTPMethod(method) {
+			return fmt.Errorf("unsupported method %q", method)
+		}
+	}
+
+	if err := d.Response.Compile(); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	return nil
+}
+
+// ParseRoutes parses a routes file, in either YAML or JSON, and validates
+// the routes it defines.
+//
+// The returned error describes every invalid route, identified by its
+// position in the file.
+func ParseRoutes(r io.Reader) ([]RouteDefinition, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file RoutesFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed parsing routes: %w", err)
+	}
+
+	var errs []error
+	for i := range file.Routes {
+		route := &file.Routes[i]
+		if err := route.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("route %d (%s): %w", i+1, route.Path, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid routes: %w", errors.Join(errs...))
+	}
+
+	return file.Routes, nil
+}
+
+// RegisterRoutes registers the routes on the Echo instance.
+//
+// Routes registered after the server's own routes, with the same method and
+// path, take precedence over them.
+func RegisterRoutes(e *echo.Echo, routes []RouteDefinition) {
+	for _, route := range routes {
+		response := route.Response
+		handler := func(ctx echo.Context) error {
+			return response.Write(ctx)
+		}
+
+		methods := route.Methods
+		if len(methods) == 0 {
+			methods = []string{http.MethodGet}
+		}
+
+		path := openAPIParamRegexp.ReplaceAllString(route.Path, ":$1")
+		for _, method := range methods {
+			e.Add(strings.ToUpper(method), path, handler)
+		}
+	}
+}
+
+// isHTTPMethod returns true if method is a standard HTTP method, case insensitively.
+func isHTTPMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
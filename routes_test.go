@@ -0,0 +1,175 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoutes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		file        string
+		want        []RouteDefinition
+		wantErrText []string
+	}{
+		{
+			name: "YAML routes should be parsed",
+			file: `
+routes:
+  - methods: [GET, POST]
+    path: /users/{id}
+    response:
+      status: 201
+      content_type: application/json
+      body: '{"id": 1}'
+      latency: 10ms-20ms
+      size: 1kb
+`,
+			want: []RouteDefinition{
+				{
+					Methods: []string{http.MethodGet, http.MethodPost},
+					Path:    "/users/{id}",
+					Response: ResponseDefinition{
+						Status:      http.StatusCreated,
+						ContentType: "application/json",
+						Body:        `{"id": 1}`,
+						Latency:     Latency{LowerBound: 10 * time.Millisecond, UpperBound: 20 * time.Millisecond},
+						Size:        &Size{LowerBound: Kilobyte},
+					},
+				},
+			},
+		},
+		{
+			name: "JSON routes should be parsed",
+			file: `{"routes": [{"path": "/health", "response": {"body": "ok"}}]}`,
+			want: []RouteDefinition{
+				{Path: "/health", Response: ResponseDefinition{Body: "ok"}},
+			},
+		},
+		{
+			name: "empty file should define no routes",
+			file: ``,
+		},
+		{
+			name:        "unknown fields should fail",
+			file:        `{"routes": [{"path": "/health", "reponse": {}}]}`,
+			wantErrText: []string{"reponse"},
+		},
+		{
+			name:        "invalid latency should fail",
+			file:        `{"routes": [{"path": "/health", "response": {"latency": "soon"}}]}`,
+			wantErrText: []string{"failed parsing routes"},
+		},
+		{
+			name: "every invalid route should be reported",
+			file: `
+routes:
+  - path: health
+  - path: /ok
+  - path: /teapot
+    methods: [BREW]
+  - path: /status
+    response:
+      status: 1000
+`,
+			wantErrText: []string{
+				`route 1 (health): path "health" must start with a /`,
+				`route 3 (/teapot): unsupported method "BREW"`,
+				`route 4 (/status): invalid response: status must be between 100 and 599`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRoutes(strings.NewReader(tt.file))
+			if tt.wantErrText != nil {
+				require.Error(t, err)
+				for _, text := range tt.wantErrText {
+					assert.Contains(t, err.Error(), text)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterRoutes(e, []RouteDefinition{
+		{
+			Methods:  []string{"get", "delete"},
+			Path:     "/users/{id}",
+			Response: ResponseDefinition{Status: http.StatusAccepted, Body: "accepted"},
+		},
+		{
+			Path:     "/health",
+			Response: ResponseDefinition{Body: "ok"},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "route should respond to its methods",
+			method:     http.MethodDelete,
+			target:     "/users/42",
+			wantStatus: http.StatusAccepted,
+			wantBody:   "accepted",
+		},
+		{
+			name:       "route should not respond to other methods",
+			method:     http.MethodPost,
+			target:     "/users/42",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "route without methods should respond to GET",
+			method:     http.MethodGet,
+			target:     "/health",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
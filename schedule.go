@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoSchedule is returned when no schedule is set.
+var ErrNoSchedule = errors.New("no schedule is set")
+
+// Duration is a time.Duration expressed, in text, in the format of
+// time.ParseDuration, such as "1m30s".
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+
+	return nil
+}
+
+// Schedule describes how the latency and errors injected into every request
+// evolve over a run, as a sequence of stages.
+//
+// Once its last stage is over, the schedule either starts over, or stops
+// overriding the runtime settings.
+type Schedule struct {
+	// Stages are the stages of the schedule, in order.
+	Stages []ScheduleStage `json:"stages" yaml:"stages"`
+
+	// Repeat starts the schedule over once its last stage is over.
+	Repeat bool `json:"repeat,omitempty" yaml:"repeat,omitempty"`
+}
+
+// ScheduleStage describes the latency and errors injected into every request
+// for a period of time.
+type ScheduleStage struct {
+	// Duration is how long the stage lasts.
+	Duration Duration `json:"duration" yaml:"duration"`
+
+	// Latency is waited for before handling every request.
+	Latency Latency `json:"latency" yaml:"latency"`
+
+	// ErrorRate is the probability, between 0 and 1, for a request to be
+	// answered with an error rather than handled.
+	ErrorRate float64 `json:"error_rate" yaml:"error_rate"`
+
+	// ErrorStatus is the status of the injected errors. Defaults to the one
+	// of the runtime settings.
+	ErrorStatus int `json:"error_status,omitempty" yaml:"error_status,omitempty"`
+
+	// Ramp changes the latency and error rate linearly, over the stage's
+	// duration, from the ones of the previous stage to the ones of this stage.
+	// The first stage ramps from the runtime settings.
+	Ramp bool `json:"ramp,omitempty" yaml:"ramp,omitempty"`
+}
+
+// Validate checks if the ScheduleStage struct satisfies the defined constraints.
+func (s ScheduleStage) Validate() error {
+	if s.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+
+	if err := s.Latency.Validate(); err != nil {
+		return fmt.Errorf("invalid latency: %w", err)
+	}
+
+	if s.ErrorRate < 0 || s.ErrorRate > 1 {
+		return errors.New("error rate must be between 0 and 1")
+	}
+
+	if s.ErrorStatus != 0 && (s.ErrorStatus < 100 || s.ErrorStatus > 599) {
+		return errors.New("error status must be between 100 and 599")
+	}
+
+	return nil
+}
+
+// Validate checks if the Schedule struct satisfies the defined constraints.
+func (s Schedule) Validate() error {
+	if len(s.Stages) == 0 {
+		return errors.New("schedule must have at least one stage")
+	}
+
+	var errs []error
+	for i, stage := range s.Stages {
+		if err := stage.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("stage %d: %w", i+1, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Duration returns the total duration of the schedule's stages.
+func (s Schedule) Duration() time.Duration {
+	var total time.Duration
+	for _, stage := range s.Stages {
+		total += time.Duration(stage.Duration)
+	}
+
+	return total
+}
+
+// At returns the settings in effect once elapsed has passed since the
+// schedule started, as the base settings overridden by the current stage,
+// along with the index of the current stage.
+//
+// It returns the base settings, and false, if the schedule is over.
+func (s Schedule) At(base Settings, elapsed time.Duration) (Settings, int, bool) {
+	total := s.Duration()
+	if total <= 0 || elapsed < 0 || (elapsed >= total && !s.Repeat) {
+		return base, -1, false
+	}
+	elapsed %= total
+
+	from := base
+	for i, stage := range s.Stages {
+		to := stage.apply(base)
+
+		duration := time.Duration(stage.Duration)
+		if elapsed < duration {
+			if stage.Ramp {
+				return interpolateSettings(from, to, float64(elapsed)/float64(duration)), i, true
+			}
+
+			return to, i, true
+		}
+
+		elapsed -= duration
+		from = to
+	}
+
+	return base, -1, false
+}
+
+// apply returns the base settings overridden by the stage.
+func (s ScheduleStage) apply(base Settings) Settings {
+	settings := base
+	settings.Latency = s.Latency
+	settings.ErrorRate = s.ErrorRate
+	if s.ErrorStatus != 0 {
+		settings.ErrorStatus = s.ErrorStatus
+	}
+
+	return settings
+}
+
+// interpolateSettings returns the settings the given progress, between 0
+// and 1, of the way from one settings to the other.
+func interpolateSettings(from, to Settings, progress float64) Settings {
+	settings := to
+	settings.ErrorRate = from.ErrorRate + (to.ErrorRate-from.ErrorRate)*progress
+	settings.Latency = interpolateLatency(from.Latency, to.Latency, progress)
+
+	return settings
+}
+
+// interpolateLatency returns the latency the given progress, between 0 and
+// 1, of the way from one latency to the other, bound by bound.
+func interpolateLatency(from, to Latency, progress float64) Latency {
+	upperBound := func(l Latency) time.Duration {
+		if l.HasBounds() {
+			return l.UpperBound
+		}
+		return l.LowerBound
+	}
+
+	interpolate := func(from, to time.Duration) time.Duration {
+		return from + time.Duration(float64(to-from)*progress)
+	}
+
+	lower := interpolate(from.LowerBound, to.LowerBound)
+	upper := interpolate(upperBound(from), upperBound(to))
+	if lower <= 0 || upper <= lower {
+		return Latency{LowerBound: lower}
+	}
+
+	return Latency{LowerBound: lower, UpperBound: upper}
+}
+
+// ScheduleStatus describes the progress of the schedule applied to the
+// runtime settings.
+type ScheduleStatus struct {
+	// Schedule is the applied schedule.
+	Schedule Schedule `json:"schedule"`
+
+	// Started is the time the current run of the schedule started at.
+	Started time.Time `json:"started"`
+
+	// Elapsed is the time elapsed since the current run started.
+	Elapsed Duration `json:"elapsed"`
+
+	// Active is true until the schedule is over.
+	Active bool `json:"active"`
+
+	// Stage is the index of the current stage, starting at 0, or -1 if the
+	// schedule is over.
+	Stage int `json:"stage"`
+
+	// Settings are the settings currently in effect.
+	Settings Settings `json:"settings"`
+}
+
+// ParseSchedule parses a schedule, in either YAML or JSON, and validates it.
+func ParseSchedule(r io.Reader) (Schedule, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var schedule Schedule
+	if err := decoder.Decode(&schedule); err != nil {
+		return schedule, fmt.Errorf("failed parsing schedule: %w", err)
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return schedule, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// SetSchedule validates and applies the schedule to the runtime settings,
+// and starts a run of it.
+func (r *RuntimeSettings) SetSchedule(schedule Schedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schedule = &schedule
+	r.scheduleStarted = time.Now()
+
+	return nil
+}
+
+// StartSchedule starts a new run of the applied schedule.
+func (r *RuntimeSettings) StartSchedule() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schedule == nil {
+		return ErrNoSchedule
+	}
+
+	r.scheduleStarted = time.Now()
+
+	return nil
+}
+
+// ClearSchedule removes the applied schedule, if any.
+func (r *RuntimeSettings) ClearSchedule() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schedule = nil
+}
+
+// ScheduleStatus returns the progress of the applied schedule, and false if
+// no schedule is set.
+func (r *RuntimeSettings) ScheduleStatus() (ScheduleStatus, bool) {
+	base := r.Get()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.schedule == nil {
+		return ScheduleStatus{}, false
+	}
+
+	elapsed := time.Since(r.scheduleStarted)
+	settings, stage, active := r.schedule.At(base, elapsed)
+
+	return ScheduleStatus{
+		Schedule: *r.schedule,
+		Started:  r.scheduleStarted,
+		Elapsed:  Duration(elapsed),
+		Active:   active,
+		Stage:    stage,
+		Settings: settings,
+	}, true
+}
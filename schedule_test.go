@@ -0,0 +1,245 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_At(t *testing.T) {
+	t.Parallel()
+
+	base := DefaultSettings()
+	base.Bandwidth = 1024
+
+	schedule := Schedule{
+		Stages: []ScheduleStage{
+			{Duration: Duration(2 * time.Minute), Latency: Latency{LowerBound: 50 * time.Millisecond}},
+			{Duration: Duration(time.Minute), Latency: Latency{LowerBound: 800 * time.Millisecond}, Ramp: true},
+			{Duration: Duration(30 * time.Second), Latency: Latency{LowerBound: 800 * time.Millisecond}, ErrorRate: 0.2, ErrorStatus: 503},
+			{Duration: Duration(time.Minute), Ramp: true},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		repeat        bool
+		elapsed       time.Duration
+		wantLatency   Latency
+		wantErrorRate float64
+		wantStatus    int
+		wantStage     int
+		wantActive    bool
+	}{
+		{
+			name:        "first stage should apply",
+			elapsed:     time.Minute,
+			wantLatency: Latency{LowerBound: 50 * time.Millisecond},
+			wantStatus:  DefaultErrorStatus,
+			wantStage:   0,
+			wantActive:  true,
+		},
+		{
+			name:        "ramp should interpolate from the previous stage",
+			elapsed:     2*time.Minute + 30*time.Second,
+			wantLatency: Latency{LowerBound: 425 * time.Millisecond},
+			wantStatus:  DefaultErrorStatus,
+			wantStage:   1,
+			wantActive:  true,
+		},
+		{
+			name:          "stage should override the error rate and status",
+			elapsed:       3*time.Minute + 10*time.Second,
+			wantLatency:   Latency{LowerBound: 800 * time.Millisecond},
+			wantErrorRate: 0.2,
+			wantStatus:    503,
+			wantStage:     2,
+			wantActive:    true,
+		},
+		{
+			name:          "recovery ramp should interpolate back to no latency and errors",
+			elapsed:       3*time.Minute + 30*time.Second + 45*time.Second,
+			wantLatency:   Latency{LowerBound: 200 * time.Millisecond},
+			wantErrorRate: 0.05,
+			wantStatus:    DefaultErrorStatus,
+			wantStage:     3,
+			wantActive:    true,
+		},
+		{
+			name:       "over schedule should return the base settings",
+			elapsed:    time.Hour,
+			wantStatus: DefaultErrorStatus,
+			wantStage:  -1,
+		},
+		{
+			name:        "repeated schedule should start over",
+			repeat:      true,
+			elapsed:     4*time.Minute + 30*time.Second + time.Minute,
+			wantLatency: Latency{LowerBound: 50 * time.Millisecond},
+			wantStatus:  DefaultErrorStatus,
+			wantStage:   0,
+			wantActive:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule := schedule
+			schedule.Repeat = tt.repeat
+
+			got, stage, active := schedule.At(base, tt.elapsed)
+
+			assert.Equal(t, tt.wantLatency, got.Latency)
+			assert.InDelta(t, tt.wantErrorRate, got.ErrorRate, 1e-9)
+			assert.Equal(t, tt.wantStatus, got.ErrorStatus)
+			assert.Equal(t, tt.wantStage, stage)
+			assert.Equal(t, tt.wantActive, active)
+			assert.Equal(t, base.Bandwidth, got.Bandwidth, "settings the schedule does not cover should be untouched")
+		})
+	}
+}
+
+func TestInterpolateLatency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		from     Latency
+		to       Latency
+		progress float64
+		want     Latency
+	}{
+		{
+			name:     "fixed latencies should interpolate",
+			from:     Latency{LowerBound: 100 * time.Millisecond},
+			to:       Latency{LowerBound: 200 * time.Millisecond},
+			progress: 0.5,
+			want:     Latency{LowerBound: 150 * time.Millisecond},
+		},
+		{
+			name:     "latency ranges should interpolate bound by bound",
+			from:     Latency{LowerBound: 100 * time.Millisecond},
+			to:       Latency{LowerBound: 200 * time.Millisecond, UpperBound: 400 * time.Millisecond},
+			progress: 0.5,
+			want:     Latency{LowerBound: 150 * time.Millisecond, UpperBound: 250 * time.Millisecond},
+		},
+		{
+			name:     "ranges closing in should produce a fixed latency",
+			from:     Latency{LowerBound: 100 * time.Millisecond, UpperBound: 300 * time.Millisecond},
+			to:       Latency{LowerBound: 200 * time.Millisecond, UpperBound: 200 * time.Millisecond},
+			progress: 1,
+			want:     Latency{LowerBound: 200 * time.Millisecond},
+		},
+		{
+			name:     "zero lower bound should produce a fixed latency",
+			from:     Latency{},
+			to:       Latency{LowerBound: 200 * time.Millisecond},
+			progress: 0,
+			want:     Latency{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, interpolateLatency(tt.from, tt.to, tt.progress))
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := ParseSchedule(strings.NewReader(`
+repeat: true
+stages:
+  - duration: 2m
+    latency: 50ms
+  - duration: 1m
+    latency: 800ms
+    ramp: true
+  - duration: 30s
+    error_rate: 0.2
+    error_status: 503
+`))
+	require.NoError(t, err)
+	assert.True(t, schedule.Repeat)
+	require.Len(t, schedule.Stages, 3)
+	assert.Equal(t, Duration(2*time.Minute), schedule.Stages[0].Duration)
+	assert.Equal(t, Latency{LowerBound: 800 * time.Millisecond}, schedule.Stages[1].Latency)
+	assert.True(t, schedule.Stages[1].Ramp)
+	assert.Equal(t, 503, schedule.Stages[2].ErrorStatus)
+	assert.Equal(t, 3*time.Minute+30*time.Second, schedule.Duration())
+
+	_, err = ParseSchedule(strings.NewReader(`stages: []`))
+	assert.ErrorContains(t, err, "at least one stage")
+
+	_, err = ParseSchedule(strings.NewReader("stages:\n  - duration: 1m\n    error_rate: 2\n  - latency: 1s"))
+	assert.ErrorContains(t, err, "stage 1: error rate must be between 0 and 1")
+	assert.ErrorContains(t, err, "stage 2: duration must be positive")
+
+	_, err = ParseSchedule(strings.NewReader("stages:\n  - duration: soon"))
+	assert.Error(t, err)
+}
+
+func TestRuntimeSettings_Schedule(t *testing.T) {
+	t.Parallel()
+
+	settings := NewRuntimeSettings(DefaultSettings())
+
+	_, ok := settings.ScheduleStatus()
+	assert.False(t, ok)
+	assert.ErrorIs(t, settings.StartSchedule(), ErrNoSchedule)
+
+	require.NoError(t, settings.SetSchedule(Schedule{
+		Stages: []ScheduleStage{{Duration: Duration(time.Hour), ErrorRate: 1}},
+	}))
+	assert.Equal(t, 1.0, settings.Current().ErrorRate, "current settings should follow the schedule")
+	assert.Equal(t, 0.0, settings.Get().ErrorRate, "settings should be left untouched by the schedule")
+
+	status, ok := settings.ScheduleStatus()
+	require.True(t, ok)
+	assert.True(t, status.Active)
+	assert.Equal(t, 0, status.Stage)
+	assert.NoError(t, settings.StartSchedule())
+
+	settings.ClearSchedule()
+	assert.Equal(t, 0.0, settings.Current().ErrorRate)
+}
+
+func TestRuntimeSettings_ScheduleConstantLatency(t *testing.T) {
+	t.Parallel()
+
+	settings := NewRuntimeSettings(DefaultSettings())
+	require.NoError(t, settings.SetSchedule(Schedule{
+		Stages: []ScheduleStage{
+			{Duration: Duration(time.Hour), Latency: Latency{LowerBound: 10 * time.Millisecond, UpperBound: 10 * time.Millisecond}},
+		},
+	}))
+
+	e := echo.New()
+	e.Use(SettingsMiddleware(settings))
+	e.GET("/", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,7 +12,41 @@ import (
 )
 
 // ServerImpl is an implementation of the OpenAPI ServerInterface.
-type ServerImpl struct{}
+//
+// Stateless handlers can be served by a zero ServerImpl, stateful ones
+// require it to be created using NewServerImpl.
+type ServerImpl struct {
+	// metrics holds the metrics exposed on the /metrics endpoint.
+	metrics *Metrics
+
+	// rateLimit holds the default rate limit options.
+	rateLimit RateLimitOptions
+
+	// rateLimiters holds the state of the rate limiters.
+	rateLimiters *RateLimiters
+
+	// storeOptions holds the options of the resource store.
+	storeOptions StoreOptions
+
+	// store holds the resources served by the /store endpoints.
+	store *Store
+
+	// started is the time the server was created at, and the version of
+	// the payloads served by the /cache endpoint.
+	started time.Time
+}
+
+// NewServerImpl creates a new ServerImpl instance from the provided configuration.
+func NewServerImpl(config Config) *ServerImpl {
+	return &ServerImpl{
+		metrics:      NewMetrics(),
+		rateLimit:    config.RateLimit,
+		rateLimiters: NewRateLimiters(),
+		storeOptions: config.Store,
+		store:        NewStore(config.Store.Capacity),
+		started:      time.Now(),
+	}
+}
 
 var _ ServerInterface = &ServerImpl{}
 
@@ -20,9 +55,23 @@ var _ ServerInterface = &ServerImpl{}
 // It responds with a JSON payload describing the API surface.
 func (s *ServerImpl) Get(ctx echo.Context) error {
 	apiDescription := map[string]string{
-		"/":                   "Root Endpoint",
-		"/latency/{duration}": "Get a response within the provided latency duration",
-		"/data/{size}":        "Get a response with a payload matching the provided size criteria",
+		"/":                              "Root Endpoint",
+		"/latency/{duration}":            "Get a response within the provided latency duration",
+		"/data/{size}":                   "Get a response with a payload matching the provided size criteria",
+		"/cache/{size}":                  "Get a cacheable payload, honouring conditional requests",
+		"/response":                      "Get a response with the provided status and content type",
+		"/redirect/{hops}":               "Get redirected {hops} times before reaching the final destination",
+		"/cookies":                       "Get the cookies sent by the client",
+		"/cookies/set":                   "Set cookies with the provided attributes",
+		"/cookies/delete":                "Delete the cookies with the provided names",
+		"/auth/basic/{user}/{password}":  "Get a response requiring HTTP Basic authentication",
+		"/auth/digest/{user}/{password}": "Get a response requiring HTTP Digest authentication",
+		"/auth/bearer":                   "Get a response requiring a bearer token",
+		"/auth/apikey/{key}":             "Get a response requiring an API key",
+		"/ratelimit/{bucket}":            "Get a response subject to a rate limit",
+		"/metrics":                       "Get the server's metrics",
+		"/store/{collection}":            "List or create the resources of a collection",
+		"/store/{collection}/{id}":       "Get, replace, update or delete a resource",
 	}
 
 	if err := ctx.JSON(http.StatusOK, apiDescription); err != nil {
@@ -35,10 +84,13 @@ func (s *ServerImpl) Get(ctx echo.Context) error {
 // GetDataSize is a handler returning a payload of the requested size.
 //
 // It parses the {size} parameter from the URL to determine the size bounds.
-// It generates a random payload matching those bounds.
+// It generates a payload matching those bounds from the requested seed, or
+// from a random one.
 //
-// The payload is returned as a Blob with Content-Type "application/octet-stream".
-func (s *ServerImpl) GetDataSize(ctx echo.Context, size string) error {
+// The payload is returned with Content-Type "application/octet-stream", and
+// an ETag identifying its seed. Range and If-Range headers are honoured,
+// with partial content responses.
+func (s *ServerImpl) GetDataSize(ctx echo.Context, size string, params GetDataSizeParams) error {
 	// Compute size bounds
 	sizeBounds, err := ParseSize(size)
 	if err != nil {
@@ -63,10 +115,20 @@ func (s *ServerImpl) GetDataSize(ctx echo.Context, size string) error {
 		return ctx.String(http.StatusBadRequest, err.Error())
 	}
 
-	// Write the data to the response
-	payload := sizeBounds.Payload()
+	// Derive the default seed from the size, so that the same URL always
+	// responds with the same payload
+	seed := cacheSeed(sizeBounds, time.Time{})
+	if params.Seed != nil {
+		seed = *params.Seed
+	}
+
+	// ServeContent honours the Range and If-Range headers
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentType, "application/octet-stream")
+	header.Set("ETag", cacheETag(sizeBounds, seed, false))
+	http.ServeContent(ctx.Response(), ctx.Request(), "", time.Time{}, bytes.NewReader(sizeBounds.SeededPayload(seed)))
 
-	return ctx.Blob(http.StatusOK, "application/octet-stream", payload)
+	return nil
 }
 
 // GetLatencyDuration is a handler that waits for the specified duration before responding.
@@ -139,6 +201,35 @@ func (s *ServerImpl) GetResponse(ctx echo.Context, params GetResponseParams) err
 		return ctx.NoContent(status)
 	}
 
+	// Respond with the provided body, rendered as a template if requested
+	if params.Body != nil {
+		body := []byte(*params.Body)
+		if params.Template != nil && *params.Template {
+			tmpl, err := ParseResponseTemplate(*params.Body)
+			if err != nil {
+				slog.Error(
+					"invalid response template",
+					"handler", "GetResponse",
+					"error_message", err.Error(),
+				)
+
+				return ctx.String(http.StatusBadRequest, err.Error())
+			}
+
+			if body, err = ExecuteResponseTemplate(ctx, tmpl); err != nil {
+				slog.Error(
+					"failed rendering response template",
+					"handler", "GetResponse",
+					"error_message", err.Error(),
+				)
+
+				return ctx.String(http.StatusBadRequest, err.Error())
+			}
+		}
+
+		return ctx.Blob(status, contentType, body)
+	}
+
 	if contentType == "application/json" {
 		body := map[string]interface{}{
 			"status":       status,
@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -35,12 +36,141 @@ func TestGetDataSizeHandler(t *testing.T) {
 			c := e.NewContext(req, rec)
 			handler := &ServerImpl{}
 
-			assert.NoError(t, handler.GetDataSize(c, tt.size))
+			assert.NoError(t, handler.GetDataSize(c, tt.size, GetDataSizeParams{}))
 			assert.Equal(t, tt.wantStatus, rec.Code)
 		})
 	}
 }
 
+func TestGetDataSizeHandler_Range(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(42)
+	full := Size{LowerBound: 100}.SeededPayload(seed)
+	etag := cacheETag(Size{LowerBound: 100}, seed, false)
+
+	tests := []struct {
+		name             string
+		header           http.Header
+		wantStatus       int
+		wantContentRange string
+		wantContentType  string
+		wantBody         []byte
+	}{
+		{
+			name:            "no range should respond with the full payload",
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMEOctetStream,
+			wantBody:        full,
+		},
+		{
+			name:             "range should respond with partial content",
+			header:           http.Header{"Range": {"bytes=0-9"}},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 0-9/100",
+			wantContentType:  echo.MIMEOctetStream,
+			wantBody:         full[0:10],
+		},
+		{
+			name:             "open range should respond with the end of the payload",
+			header:           http.Header{"Range": {"bytes=90-"}},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 90-99/100",
+			wantContentType:  echo.MIMEOctetStream,
+			wantBody:         full[90:],
+		},
+		{
+			name:            "multiple ranges should respond with multipart byteranges",
+			header:          http.Header{"Range": {"bytes=0-9,20-29"}},
+			wantStatus:      http.StatusPartialContent,
+			wantContentType: "multipart/byteranges",
+		},
+		{
+			name:             "unsatisfiable range should fail",
+			header:           http.Header{"Range": {"bytes=200-300"}},
+			wantStatus:       http.StatusRequestedRangeNotSatisfiable,
+			wantContentRange: "bytes */100",
+		},
+		{
+			name:             "matching If-Range should respond with partial content",
+			header:           http.Header{"Range": {"bytes=0-9"}, "If-Range": {etag}},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 0-9/100",
+			wantContentType:  echo.MIMEOctetStream,
+			wantBody:         full[0:10],
+		},
+		{
+			name:            "mismatching If-Range should respond with the full payload",
+			header:          http.Header{"Range": {"bytes=0-9"}, "If-Range": {`"other"`}},
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMEOctetStream,
+			wantBody:        full,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/data/100b?seed=42", nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			handler := &ServerImpl{}
+
+			assert.NoError(t, handler.GetDataSize(c, "100b", GetDataSizeParams{Seed: &seed}))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantContentRange, rec.Header().Get("Content-Range"))
+			assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), tt.wantContentType))
+			if tt.wantStatus != http.StatusRequestedRangeNotSatisfiable {
+				assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+				assert.Equal(t, etag, rec.Header().Get("ETag"))
+			}
+			if tt.wantBody != nil {
+				assert.Equal(t, tt.wantBody, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestGetDataSizeHandler_ResumeWithoutSeed(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []string{"100b", "1kb-2kb"} {
+		size := size
+
+		t.Run(size, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			handler := &ServerImpl{}
+
+			first := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/data/"+size, nil)
+			assert.NoError(t, handler.GetDataSize(e.NewContext(req, first), size, GetDataSizeParams{}))
+			assert.Equal(t, http.StatusOK, first.Code)
+
+			full := first.Body.Bytes()
+			etag := first.Header().Get("ETag")
+
+			resumed := httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/data/"+size, nil)
+			req.Header.Set("Range", "bytes=50-")
+			req.Header.Set("If-Range", etag)
+			assert.NoError(t, handler.GetDataSize(e.NewContext(req, resumed), size, GetDataSizeParams{}))
+
+			assert.Equal(t, http.StatusPartialContent, resumed.Code)
+			assert.Equal(t, etag, resumed.Header().Get("ETag"))
+			assert.Equal(t, full[50:], resumed.Body.Bytes(), "resumed download should continue the same payload")
+		})
+	}
+}
+
 func TestGetLatencyDurationHandler(t *testing.T) {
 	t.Parallel()
 
@@ -80,6 +210,8 @@ func TestGetResponse(t *testing.T) {
 		name           string
 		status         int
 		contentType    string
+		body           string
+		template       bool
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -106,6 +238,27 @@ func TestGetResponse(t *testing.T) {
 			expectedStatus: http.StatusNoContent,
 			expectedBody:   "",
 		},
+		{
+			name:           "custom body",
+			body:           "{{ .Query.token }}",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{{ .Query.token }}",
+		},
+		{
+			name:           "template body",
+			contentType:    "application/json",
+			body:           `{"token":"{{ .Query.token }}","user":{{ json (index .Headers "X-User") }}}`,
+			template:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"token":"abc","user":"alice"}`,
+		},
+		{
+			name:           "invalid template body",
+			body:           "{{ .Query.token",
+			template:       true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "template: response:1: unclosed action",
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,7 +269,8 @@ func TestGetResponse(t *testing.T) {
 
 			e := echo.New()
 
-			req := httptest.NewRequest(http.MethodGet, "/response", nil)
+			req := httptest.NewRequest(http.MethodGet, "/response?token=abc", nil)
+			req.Header.Set("X-User", "alice")
 			if tt.contentType != "" {
 				req.Header.Set("Content-Type", tt.contentType)
 			}
@@ -125,6 +279,10 @@ func TestGetResponse(t *testing.T) {
 			if tt.status != 0 {
 				responseParams.Status = &tt.status
 			}
+			if tt.body != "" {
+				responseParams.Body = &tt.body
+				responseParams.Template = &tt.template
+			}
 
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
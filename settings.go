@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultErrorStatus is the status of the injected errors when none is specified.
+const DefaultErrorStatus = http.StatusInternalServerError
+
+// ErrInvalidSettings is returned when the runtime settings are invalid.
+var ErrInvalidSettings = errors.New("invalid settings")
+
+// Settings holds the default behaviour applied to every route of the server.
+type Settings struct {
+	// Latency is a baseline latency waited for before handling every request.
+	Latency Latency `json:"latency"`
+
+	// ErrorRate is the probability, between 0 and 1, for a request to be
+	// answered with an error rather than handled.
+	ErrorRate float64 `json:"error_rate"`
+
+	// ErrorStatus is the status of the injected errors.
+	ErrorStatus int `json:"error_status"`
+
+	// Bandwidth caps the transfer rate of the responses' bodies. Zero means unlimited.
+	Bandwidth Bandwidth `json:"bandwidth"`
+
+	// DisabledRoutes holds the routes answered with a 404 rather than handled.
+	// Routes are expressed either as registered, such as "/data/:size", in the
+	// OpenAPI style, such as "/data/{size}", or as literal request paths.
+	DisabledRoutes []string `json:"disabled_routes"`
+}
+
+// DefaultSettings returns the settings the server starts with.
+func DefaultSettings() Settings {
+	return Settings{
+		ErrorStatus:    DefaultErrorStatus,
+		DisabledRoutes: []string{},
+	}
+}
+
+// Validate checks if the Settings struct satisfies the defined constraints.
+func (s Settings) Validate() error {
+	if err := s.Latency.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+
+	if s.ErrorRate < 0 || s.ErrorRate > 1 {
+		return fmt.Errorf("%w: error rate must be between 0 and 1", ErrInvalidSettings)
+	}
+
+	if s.ErrorStatus < 100 || s.ErrorStatus > 599 {
+		return fmt.Errorf("%w: error status must be between 100 and 599", ErrInvalidSettings)
+	}
+
+	if s.Bandwidth < 0 {
+		return fmt.Errorf("%w: bandwidth cannot be negative", ErrInvalidSettings)
+	}
+
+	return nil
+}
+
+// isDisabled returns true if the route, or the literal request path, is disabled.
+func (s Settings) isDisabled(route string, path string) bool {
+	for _, disabled := range s.DisabledRoutes {
+		disabled = openAPIParamRegexp.ReplaceAllString(disabled, ":$1")
+		if disabled == route || disabled == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openAPIParamRegexp matches the OpenAPI style path parameters, such as {size}.
+var openAPIParamRegexp = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// RuntimeSettings holds the settings of a running server, and allows
+// changing them without restarting it. A schedule can be applied on top
+// of them, to change the latency and errors over time.
+//
+// It is safe for concurrent use.
+type RuntimeSettings struct {
+	mu       sync.RWMutex
+	settings Settings
+
+	schedule        *Schedule
+	scheduleStarted time.Time
+}
+
+// NewRuntimeSettings creates a new RuntimeSettings instance holding the provided settings.
+func NewRuntimeSettings(settings Settings) *RuntimeSettings {
+	return &RuntimeSettings{settings: settings}
+}
+
+// Get returns a copy of the current settings.
+func (r *RuntimeSettings) Get() Settings {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := r.settings
+	settings.DisabledRoutes = append([]string{}, r.settings.DisabledRoutes...)
+
+	return settings
+}
+
+// Current returns a copy of the settings in effect: the current settings,
+// overridden by the applied schedule, if any.
+func (r *RuntimeSettings) Current() Settings {
+	settings := r.Get()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.schedule != nil {
+		settings, _, _ = r.schedule.At(settings, time.Since(r.scheduleStarted))
+	}
+
+	return settings
+}
+
+// Set validates and replaces the current settings.
+func (r *RuntimeSettings) Set(settings Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	if settings.DisabledRoutes == nil {
+		settings.DisabledRoutes = []string{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings = settings
+
+	return nil
+}
+
+// SettingsMiddleware returns a middleware applying the runtime settings to
+// every request whose path does not start with one of the skipped prefixes.
+//
+// It answers disabled routes with a 404, waits for the baseline latency,
+// injects errors at the configured rate, and caps the bandwidth of the
+// response, in that order. The latency and errors follow the applied
+// schedule, if any, and the behaviour profile of the listener which accepted
+// the request's connection overrides them.
+func SettingsMiddleware(settings *RuntimeSettings, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			path := ctx.Request().URL.Path
+			if hasAnyPathPrefix(path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			current := settings.Current()
+			if profile, ok := listenerProfile(ctx.Request().Context()); ok {
+				current = profile.apply(current)
+			}
+
+			if current.isDisabled(ctx.Path(), path) {
+				return ctx.String(http.StatusNotFound, "route disabled")
+			}
+
+			current.Latency.Wait()
+
+			//nolint:gosec
+			if current.ErrorRate > 0 && rand.Float64() < current.ErrorRate {
+				return ctx.String(current.ErrorStatus, "injected error")
+			}
+
+			if current.Bandwidth > 0 {
+				ctx.Response().Writer = newThrottledWriter(ctx.Response().Writer, current.Bandwidth)
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings Settings
+		wantErr  bool
+	}{
+		{
+			name:     "default settings should be valid",
+			settings: DefaultSettings(),
+		},
+		{
+			name:     "error rate above 1 should fail",
+			settings: Settings{ErrorRate: 1.5, ErrorStatus: http.StatusInternalServerError},
+			wantErr:  true,
+		},
+		{
+			name:     "negative error rate should fail",
+			settings: Settings{ErrorRate: -0.1, ErrorStatus: http.StatusInternalServerError},
+			wantErr:  true,
+		},
+		{
+			name:     "out of range error status should fail",
+			settings: Settings{ErrorStatus: 42},
+			wantErr:  true,
+		},
+		{
+			name:     "latency with equal bounds should be valid",
+			settings: Settings{Latency: Latency{LowerBound: 10 * time.Millisecond, UpperBound: 10 * time.Millisecond}, ErrorStatus: http.StatusInternalServerError},
+		},
+		{
+			name:     "invalid latency should fail",
+			settings: Settings{Latency: Latency{LowerBound: -time.Second}, ErrorStatus: http.StatusInternalServerError},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.settings.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSettings)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSettingsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		settings   Settings
+		target     string
+		wantStatus int
+	}{
+		{
+			name:       "default settings should leave requests untouched",
+			settings:   DefaultSettings(),
+			target:     "/data/1kb",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "disabled route should respond with a 404",
+			settings:   Settings{ErrorStatus: http.StatusInternalServerError, DisabledRoutes: []string{"/data/:size"}},
+			target:     "/data/1kb",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "disabled route in the OpenAPI style should respond with a 404",
+			settings:   Settings{ErrorStatus: http.StatusInternalServerError, DisabledRoutes: []string{"/data/{size}"}},
+			target:     "/data/1kb",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "disabled literal path should respond with a 404",
+			settings:   Settings{ErrorStatus: http.StatusInternalServerError, DisabledRoutes: []string{"/data/1kb"}},
+			target:     "/data/1kb",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "error rate of 1 should always inject errors",
+			settings:   Settings{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			target:     "/data/1kb",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "latency with equal bounds should be waited for",
+			settings:   Settings{Latency: Latency{LowerBound: 10 * time.Millisecond, UpperBound: 10 * time.Millisecond}, ErrorStatus: http.StatusInternalServerError},
+			target:     "/data/1kb",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "skipped prefixes should be left untouched",
+			settings:   Settings{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			target:     "/admin/settings",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			e.Use(SettingsMiddleware(NewRuntimeSettings(tt.settings), "/admin"))
+			ok := func(ctx echo.Context) error { return ctx.String(http.StatusOK, "ok") }
+			e.GET("/data/:size", ok)
+			e.GET("/admin/settings", ok)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
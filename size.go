@@ -130,6 +130,33 @@ func (s Size) String() string {
 	return fmt.Sprintf("%d-%d", s.LowerBound, s.UpperBound)
 }
 
+// MarshalText implements encoding.TextMarshaler, using the same format
+// as ParseSize.
+func (s Size) MarshalText() ([]byte, error) {
+	if !s.HasBounds() {
+		return []byte(fmt.Sprintf("%db", s.LowerBound)), nil
+	}
+
+	return []byte(fmt.Sprintf("%db-%db", s.LowerBound, s.UpperBound)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using the same format
+// as ParseSize.
+func (s *Size) UnmarshalText(text []byte) error {
+	size, err := ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	if err = size.Validate(); err != nil {
+		return err
+	}
+
+	*s = size
+
+	return nil
+}
+
 // HasBounds returns true if the size has upper and lower bounds.
 func (s Size) HasBounds() bool {
 	// Neither bound is set
@@ -155,6 +182,22 @@ func (s Size) HasBounds() bool {
 //
 //nolint:gosec
 func (s Size) Payload() []byte {
+	return s.payload(rand.Intn)
+}
+
+// SeededPayload returns a byte slice containing a payload generated as
+// Payload does, from the provided seed.
+//
+// Payloads generated from the same size and seed are identical, including
+// their size when the Size has bounds.
+//
+//nolint:gosec
+func (s Size) SeededPayload(seed int64) []byte {
+	return s.payload(rand.New(rand.NewSource(seed)).Intn)
+}
+
+// payload generates a payload, drawing random numbers from intn.
+func (s Size) payload(intn func(n int) int) []byte {
 	// We store the alphabet used to generate the payload
 	// statically on the Stack.
 	letterRunes := [...]byte{
@@ -173,7 +216,7 @@ func (s Size) Payload() []byte {
 
 	// Fill the bytes slice with random letters
 	for i := range bytes {
-		bytes[i] = letterRunes[rand.Intn(len(letterRunes))]
+		bytes[i] = letterRunes[intn(len(letterRunes))]
 	}
 
 	// If we have an upper bound, we extend the bytes
@@ -181,10 +224,10 @@ func (s Size) Payload() []byte {
 	// bytes on the way.
 	if s.HasBounds() {
 		difference := s.UpperBound - s.LowerBound
-		addedSize := rand.Intn(int(difference))
+		addedSize := intn(int(difference))
 		for i := 0; i < addedSize; i++ {
 			//nolint:makezero
-			bytes = append(bytes, letterRunes[rand.Intn(len(letterRunes))])
+			bytes = append(bytes, letterRunes[intn(len(letterRunes))])
 		}
 	}
 
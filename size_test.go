@@ -411,3 +411,22 @@ func TestParseByteUnit(t *testing.T) {
 		})
 	}
 }
+
+func TestSize_SeededPayload(t *testing.T) {
+	t.Parallel()
+
+	size := Size{LowerBound: 64 * Byte, UpperBound: 128 * Byte}
+
+	payload := size.SeededPayload(42)
+	if !reflect.DeepEqual(payload, size.SeededPayload(42)) {
+		t.Errorf("Size.SeededPayload() produced different payloads from the same seed")
+	}
+
+	if reflect.DeepEqual(payload, size.SeededPayload(43)) {
+		t.Errorf("Size.SeededPayload() produced the same payload from different seeds")
+	}
+
+	if len(payload) < 64 || len(payload) >= 128 {
+		t.Errorf("Size.SeededPayload() size = %d, want between 64 and 128", len(payload))
+	}
+}
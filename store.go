@@ -0,0 +1,522 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StoreOperation is an operation performed on the store.
+type StoreOperation string
+
+const (
+	// StoreCreate is the operation creating a resource.
+	StoreCreate StoreOperation = "create"
+
+	// StoreRead is the operation reading a resource.
+	StoreRead StoreOperation = "read"
+
+	// StoreUpdate is the operation replacing or patching a resource.
+	StoreUpdate StoreOperation = "update"
+
+	// StoreDelete is the operation deleting a resource.
+	StoreDelete StoreOperation = "delete"
+
+	// StoreList is the operation listing the resources of a collection.
+	StoreList StoreOperation = "list"
+)
+
+// DefaultStorePageLimit is the number of resources listed per page when none is specified.
+const DefaultStorePageLimit = 20
+
+var (
+	// ErrResourceNotFound is returned when a resource does not exist.
+	ErrResourceNotFound = errors.New("resource not found")
+
+	// ErrStoreFull is returned when the store has reached its capacity.
+	ErrStoreFull = errors.New("store is full")
+
+	// ErrUnknownStoreOperation is returned when a store operation is not known.
+	ErrUnknownStoreOperation = errors.New("unknown store operation")
+)
+
+// StoreOptions holds the options of the resource store.
+type StoreOptions struct {
+	// Capacity is the maximum number of resources the store holds,
+	// across all collections. Zero means unlimited.
+	Capacity int
+
+	// Latencies holds the latency applied to each operation.
+	Latencies StoreLatencies
+}
+
+// StoreLatencies holds the latency applied to each store operation.
+//
+// It implements flag.Value, and is expressed as a comma separated list of
+// operation=latency pairs, such as "create=10ms,read=1ms-5ms".
+type StoreLatencies map[StoreOperation]Latency
+
+// String returns the string representation of the latencies.
+func (l StoreLatencies) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, operation := range []StoreOperation{StoreCreate, StoreRead, StoreUpdate, StoreDelete, StoreList} {
+		if latency, ok := l[operation]; ok {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", operation, latency))
+		}
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// Set parses the operation=latency pairs, and adds them to the latencies.
+func (l StoreLatencies) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		name, duration, found := strings.Cut(pair, "=")
+		if !found {
+			return fmt.Errorf("invalid store latency %q, expected operation=latency", pair)
+		}
+
+		operation := StoreOperation(strings.TrimSpace(name))
+		switch operation {
+		case StoreCreate, StoreRead, StoreUpdate, StoreDelete, StoreList:
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownStoreOperation, operation)
+		}
+
+		latency, err := ParseValidLatency(strings.TrimSpace(duration))
+		if err != nil {
+			return fmt.Errorf("invalid %s latency: %w", operation, err)
+		}
+
+		l[operation] = latency
+	}
+
+	return nil
+}
+
+// Store is an in-memory store of JSON resources, organized in collections.
+//
+// It is safe for concurrent use.
+type Store struct {
+	mu          sync.RWMutex
+	capacity    int
+	size        int
+	collections map[string]*storeCollection
+}
+
+// storeCollection holds the resources of a collection, in creation order.
+type storeCollection struct {
+	lastID    int
+	order     []string
+	resources map[string]Resource
+}
+
+// NewStore creates a new, empty, Store holding at most capacity resources.
+// A capacity of zero means unlimited.
+func NewStore(capacity int) *Store {
+	return &Store{
+		capacity:    capacity,
+		collections: make(map[string]*storeCollection),
+	}
+}
+
+// Create adds the resource to the collection, assigning it a new identifier.
+//
+// Identifiers are assigned in sequence, skipping the ones already taken by
+// resources put under an explicit identifier.
+func (s *Store) Create(collection string, resource Resource) (Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.collection(collection)
+	var id string
+	for {
+		c.lastID++
+		id = strconv.Itoa(c.lastID)
+		if _, taken := c.resources[id]; !taken {
+			break
+		}
+	}
+
+	created, _, err := s.put(c, id, resource)
+
+	return created, err
+}
+
+// Get returns the resource with the provided identifier.
+func (s *Store) Get(collection string, id string) (Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	resource, ok := c.resources[id]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	return cloneResource(resource), nil
+}
+
+// Put replaces the resource with the provided identifier, creating it if it
+// does not exist. It returns whether the resource was created.
+func (s *Store) Put(collection string, id string, resource Resource) (Resource, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.put(s.collection(collection), id, resource)
+}
+
+// Patch applies a JSON merge patch, as described in RFC 7386, to the
+// resource with the provided identifier.
+func (s *Store) Patch(collection string, id string, patch Resource) (Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	resource, ok := c.resources[id]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	patched, _ := mergePatch(map[string]interface{}(cloneResource(resource)), map[string]interface{}(patch)).(map[string]interface{})
+	patched["id"] = id
+	c.resources[id] = patched
+
+	return cloneResource(patched), nil
+}
+
+// Delete removes the resource with the provided identifier.
+func (s *Store) Delete(collection string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return ErrResourceNotFound
+	}
+
+	if _, exists := c.resources[id]; !exists {
+		return ErrResourceNotFound
+	}
+
+	delete(c.resources, id)
+	for i, orderedID := range c.order {
+		if orderedID == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	s.size--
+
+	return nil
+}
+
+// List returns up to limit resources of the collection, skipping the first
+// offset ones, as well as the total number of resources in the collection.
+func (s *Store) List(collection string, offset, limit int) ([]Resource, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := []Resource{}
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return items, 0
+	}
+
+	for i := offset; i < len(c.order) && len(items) < limit; i++ {
+		items = append(items, cloneResource(c.resources[c.order[i]]))
+	}
+
+	return items, len(c.order)
+}
+
+// collection returns the named collection, creating it if needed.
+// It must be called with the lock held.
+func (s *Store) collection(name string) *storeCollection {
+	c, ok := s.collections[name]
+	if !ok {
+		c = &storeCollection{resources: make(map[string]Resource)}
+		s.collections[name] = c
+	}
+
+	return c
+}
+
+// put stores the resource under the provided identifier.
+// It must be called with the lock held.
+func (s *Store) put(c *storeCollection, id string, resource Resource) (Resource, bool, error) {
+	_, exists := c.resources[id]
+	if !exists && s.capacity > 0 && s.size >= s.capacity {
+		return nil, false, ErrStoreFull
+	}
+
+	stored := cloneResource(resource)
+	stored["id"] = id
+	c.resources[id] = stored
+
+	if !exists {
+		c.order = append(c.order, id)
+		s.size++
+	}
+
+	return cloneResource(stored), !exists, nil
+}
+
+// GetStoreCollection is a handler listing the resources of a collection.
+func (s *ServerImpl) GetStoreCollection(ctx echo.Context, collection StoreCollection, params GetStoreCollectionParams) error {
+	if ok, err := s.storeWait(ctx, StoreList, params.Latency); !ok {
+		return err
+	}
+
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+
+	limit := DefaultStorePageLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	if offset < 0 || limit < 0 {
+		return ctx.String(http.StatusBadRequest, "offset and limit cannot be negative")
+	}
+
+	items, total := s.store.List(collection, offset, limit)
+
+	return ctx.JSON(http.StatusOK, ResourcePage{
+		Items:  items,
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	})
+}
+
+// PostStoreCollection is a handler creating a resource in a collection.
+func (s *ServerImpl) PostStoreCollection(ctx echo.Context, collection StoreCollection, params PostStoreCollectionParams) error {
+	if ok, err := s.storeWait(ctx, StoreCreate, params.Latency); !ok {
+		return err
+	}
+
+	resource, err := bindResource(ctx)
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	created, err := s.store.Create(collection, resource)
+	if err != nil {
+		return storeError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusCreated, created)
+}
+
+// GetStoreCollectionId is a handler returning a resource.
+func (s *ServerImpl) GetStoreCollectionId(
+	ctx echo.Context,
+	collection StoreCollection,
+	id string,
+	params GetStoreCollectionIdParams,
+) error {
+	if ok, err := s.storeWait(ctx, StoreRead, params.Latency); !ok {
+		return err
+	}
+
+	resource, err := s.store.Get(collection, id)
+	if err != nil {
+		return storeError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, resource)
+}
+
+// PutStoreCollectionId is a handler replacing a resource, creating it if it does not exist.
+func (s *ServerImpl) PutStoreCollectionId(
+	ctx echo.Context,
+	collection StoreCollection,
+	id string,
+	params PutStoreCollectionIdParams,
+) error {
+	if ok, err := s.storeWait(ctx, StoreUpdate, params.Latency); !ok {
+		return err
+	}
+
+	resource, err := bindResource(ctx)
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	stored, created, err := s.store.Put(collection, id, resource)
+	if err != nil {
+		return storeError(ctx, err)
+	}
+
+	if created {
+		return ctx.JSON(http.StatusCreated, stored)
+	}
+
+	return ctx.JSON(http.StatusOK, stored)
+}
+
+// PatchStoreCollectionId is a handler applying a JSON merge patch to a resource.
+func (s *ServerImpl) PatchStoreCollectionId(
+	ctx echo.Context,
+	collection StoreCollection,
+	id string,
+	params PatchStoreCollectionIdParams,
+) error {
+	if ok, err := s.storeWait(ctx, StoreUpdate, params.Latency); !ok {
+		return err
+	}
+
+	patch, err := bindResource(ctx)
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	patched, err := s.store.Patch(collection, id, patch)
+	if err != nil {
+		return storeError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, patched)
+}
+
+// DeleteStoreCollectionId is a handler deleting a resource.
+func (s *ServerImpl) DeleteStoreCollectionId(
+	ctx echo.Context,
+	collection StoreCollection,
+	id string,
+	params DeleteStoreCollectionIdParams,
+) error {
+	if ok, err := s.storeWait(ctx, StoreDelete, params.Latency); !ok {
+		return err
+	}
+
+	if err := s.store.Delete(collection, id); err != nil {
+		return storeError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// storeWait waits for the latency of the store operation, or for the latency
+// requested by the latency parameter when provided.
+//
+// It responds with a 400 if the latency parameter is invalid, and returns
+// false along with the result of responding, for the handler to return.
+func (s *ServerImpl) storeWait(ctx echo.Context, operation StoreOperation, latencyParam *string) (bool, error) {
+	latency := s.storeOptions.Latencies[operation]
+
+	if latencyParam != nil {
+		var err error
+		if latency, err = ParseValidLatency(*latencyParam); err != nil {
+			slog.Error(
+				"failed parsing latency duration",
+				"handler", "storeWait",
+				"duration", *latencyParam,
+				"error_message", err.Error(),
+			)
+
+			return false, ctx.String(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	latency.Wait()
+
+	return true, nil
+}
+
+// bindResource decodes the request body as a JSON object.
+func bindResource(ctx echo.Context) (Resource, error) {
+	var resource Resource
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&resource); err != nil {
+		return nil, fmt.Errorf("failed decoding resource: %w", err)
+	}
+
+	if resource == nil {
+		return nil, errors.New("resource must be a JSON object")
+	}
+
+	return resource, nil
+}
+
+// storeError responds with the status matching the store error.
+func storeError(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrResourceNotFound):
+		return ctx.String(http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrStoreFull):
+		return ctx.String(http.StatusInsufficientStorage, err.Error())
+	default:
+		return ctx.String(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// mergePatch applies a JSON merge patch to the target, as described in RFC 7386.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// cloneResource returns a deep copy of the resource.
+func cloneResource(resource Resource) Resource {
+	clone, _ := cloneValue(map[string]interface{}(resource)).(map[string]interface{})
+	return clone
+}
+
+// cloneValue returns a deep copy of a decoded JSON value.
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			clone[key] = cloneValue(item)
+		}
+
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+
+		return clone
+	default:
+		return v
+	}
+}
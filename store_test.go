@@ -0,0 +1,225 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreHandlers(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, NewServerImpl(Config{Store: StoreOptions{Capacity: 2}}))
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "creating a resource should assign it an identifier",
+			method:     http.MethodPost,
+			target:     "/store/users",
+			body:       `{"name":"alice","tags":["a"]}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":"1","name":"alice","tags":["a"]}`,
+		},
+		{
+			name:       "reading a resource should return it",
+			method:     http.MethodGet,
+			target:     "/store/users/1",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":"1","name":"alice","tags":["a"]}`,
+		},
+		{
+			name:       "patching a resource should merge the patch",
+			method:     http.MethodPatch,
+			target:     "/store/users/1",
+			body:       `{"tags":null,"age":30}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":"1","name":"alice","age":30}`,
+		},
+		{
+			name:       "putting a new resource should create it",
+			method:     http.MethodPut,
+			target:     "/store/users/bob",
+			body:       `{"name":"bob"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":"bob","name":"bob"}`,
+		},
+		{
+			name:       "putting an existing resource should replace it",
+			method:     http.MethodPut,
+			target:     "/store/users/bob",
+			body:       `{"name":"robert"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":"bob","name":"robert"}`,
+		},
+		{
+			name:       "creating a resource in a full store should fail",
+			method:     http.MethodPost,
+			target:     "/store/users",
+			body:       `{"name":"carol"}`,
+			wantStatus: http.StatusInsufficientStorage,
+		},
+		{
+			name:       "listing resources should paginate them",
+			method:     http.MethodGet,
+			target:     "/store/users?offset=1&limit=1",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[{"id":"bob","name":"robert"}],"total":2,"offset":1,"limit":1}`,
+		},
+		{
+			name:       "deleting a resource should remove it",
+			method:     http.MethodDelete,
+			target:     "/store/users/1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "reading a deleted resource should fail",
+			method:     http.MethodGet,
+			target:     "/store/users/1",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "patching a missing resource should fail",
+			method:     http.MethodPatch,
+			target:     "/store/users/1",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "creating a resource from a non object body should fail",
+			method:     http.MethodPost,
+			target:     "/store/users",
+			body:       `[1, 2]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid latency should fail",
+			method:     http.MethodGet,
+			target:     "/store/users?latency=invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, step.wantStatus, rec.Code, step.name)
+		if step.wantBody != "" {
+			assert.JSONEq(t, step.wantBody, rec.Body.String(), step.name)
+		}
+	}
+}
+
+func TestStoreHandlers_CreateAfterPut(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	RegisterHandlers(e, NewServerImpl(Config{}))
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "putting a resource under a numeric identifier should create it",
+			method:     http.MethodPut,
+			target:     "/store/users/1",
+			body:       `{"name":"alice"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":"1","name":"alice"}`,
+		},
+		{
+			name:       "creating a resource should skip the identifiers already taken",
+			method:     http.MethodPost,
+			target:     "/store/users",
+			body:       `{"name":"bob"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":"2","name":"bob"}`,
+		},
+		{
+			name:       "listing resources should return both of them",
+			method:     http.MethodGet,
+			target:     "/store/users",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[{"id":"1","name":"alice"},{"id":"2","name":"bob"}],"total":2,"offset":0,"limit":20}`,
+		},
+	}
+
+	// Steps depend on each other, and are run sequentially.
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, step.wantStatus, rec.Code, step.name)
+		assert.JSONEq(t, step.wantBody, rec.Body.String(), step.name)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		target interface{}
+		patch  interface{}
+		want   interface{}
+	}{
+		{
+			name:   "patching a member should replace it",
+			target: map[string]interface{}{"a": "b"},
+			patch:  map[string]interface{}{"a": "c"},
+			want:   map[string]interface{}{"a": "c"},
+		},
+		{
+			name:   "patching a member with null should remove it",
+			target: map[string]interface{}{"a": "b", "c": "d"},
+			patch:  map[string]interface{}{"a": nil},
+			want:   map[string]interface{}{"c": "d"},
+		},
+		{
+			name:   "patching nested objects should merge them",
+			target: map[string]interface{}{"a": map[string]interface{}{"b": "c", "d": "e"}},
+			patch:  map[string]interface{}{"a": map[string]interface{}{"d": nil, "f": "g"}},
+			want:   map[string]interface{}{"a": map[string]interface{}{"b": "c", "f": "g"}},
+		},
+		{
+			name:   "patching with an array should replace the target",
+			target: map[string]interface{}{"a": []interface{}{"b"}},
+			patch:  map[string]interface{}{"a": []interface{}{"c"}},
+			want:   map[string]interface{}{"a": []interface{}{"c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, mergePatch(tt.target, tt.patch))
+		})
+	}
+}
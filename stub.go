@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ScenarioStarted is the state every scenario starts in.
+const ScenarioStarted = "Started"
+
+// ErrStubNotFound is returned when a stub does not exist.
+var ErrStubNotFound = errors.New("stub not found")
+
+// Stub describes a response served in place of the server's routes, for
+// the requests satisfying its matcher.
+type Stub struct {
+	// ID identifies the stub. IDs are assigned in registration order, starting at 1.
+	ID int64 `json:"id"`
+
+	// Priority orders the stubs: those with the highest priority are matched
+	// first. Among stubs of equal priority, the most recent one is matched first.
+	Priority int `json:"priority"`
+
+	// Request describes the requests the stub responds to.
+	Request RequestMatcher `json:"request"`
+
+	// Response describes the response served by the stub.
+	Response ResponseDefinition `json:"response"`
+
+	// Scenario is the name of the scenario the stub takes part in, if any.
+	Scenario string `json:"scenario,omitempty"`
+
+	// RequiredState is the state the scenario must be in for the stub to match.
+	// When empty, the stub matches whatever the scenario's state.
+	RequiredState string `json:"required_state,omitempty"`
+
+	// NewState is the state the scenario transitions to once the stub matched.
+	NewState string `json:"new_state,omitempty"`
+}
+
+// Validate checks if the Stub struct satisfies the defined constraints, and
+// compiles its matcher and response.
+func (s *Stub) Validate() error {
+	if err := s.Request.Compile(); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	if err := s.Response.Compile(); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	if s.Scenario == "" && (s.RequiredState != "" || s.NewState != "") {
+		return errors.New("scenario states require a scenario")
+	}
+
+	return nil
+}
+
+// Stubs holds the registered stubs, and the states of their scenarios.
+//
+// It is safe for concurrent use.
+type Stubs struct {
+	mu        sync.Mutex
+	stubs     []Stub
+	scenarios map[string]string
+	lastID    int64
+}
+
+// NewStubs creates a new, empty, Stubs instance.
+func NewStubs() *Stubs {
+	return &Stubs{scenarios: make(map[string]string)}
+}
+
+// Add validates and registers the stub, and returns it with its assigned ID.
+func (s *Stubs) Add(stub Stub) (Stub, error) {
+	if err := stub.Validate(); err != nil {
+		return stub, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	stub.ID = s.lastID
+
+	// Keep the stubs in matching order
+	s.stubs = append([]Stub{stub}, s.stubs...)
+	sort.SliceStable(s.stubs, func(i, j int) bool {
+		return s.stubs[i].Priority > s.stubs[j].Priority
+	})
+
+	if stub.Scenario != "" {
+		if _, ok := s.scenarios[stub.Scenario]; !ok {
+			s.scenarios[stub.Scenario] = ScenarioStarted
+		}
+	}
+
+	return stub, nil
+}
+
+// List returns the registered stubs, in matching order.
+func (s *Stubs) List() []Stub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Stub{}, s.stubs...)
+}
+
+// Get returns the stub with the provided ID, and false if it does not exist.
+func (s *Stubs) Get(id int64) (Stub, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stub := range s.stubs {
+		if stub.ID == id {
+			return stub, true
+		}
+	}
+
+	return Stub{}, false
+}
+
+// Delete removes the stub with the provided ID.
+func (s *Stubs) Delete(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, stub := range s.stubs {
+		if stub.ID == id {
+			s.stubs = append(s.stubs[:i], s.stubs[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrStubNotFound
+}
+
+// Clear removes every stub and scenario.
+func (s *Stubs) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stubs = nil
+	s.scenarios = make(map[string]string)
+}
+
+// Len returns the number of registered stubs.
+func (s *Stubs) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.stubs)
+}
+
+// Scenarios returns the current state of every scenario, indexed by name.
+func (s *Stubs) Scenarios() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scenarios := make(map[string]string, len(s.scenarios))
+	for name, state := range s.scenarios {
+		scenarios[name] = state
+	}
+
+	return scenarios
+}
+
+// SetScenario sets the state of a scenario.
+func (s *Stubs) SetScenario(name, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios[name] = state
+}
+
+// ResetScenarios sets every scenario back to its started state.
+func (s *Stubs) ResetScenarios() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.scenarios {
+		s.scenarios[name] = ScenarioStarted
+	}
+}
+
+// Match returns the first stub matching the request, in matching order, and
+// false if none does. The matching stub's scenario transitions to its new state.
+func (s *Stubs) Match(req CapturedRequest) (Stub, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stub := range s.stubs {
+		if stub.Scenario != "" && stub.RequiredState != "" && s.scenarios[stub.Scenario] != stub.RequiredState {
+			continue
+		}
+
+		if !stub.Request.Match(req) {
+			continue
+		}
+
+		if stub.Scenario != "" && stub.NewState != "" {
+			s.scenarios[stub.Scenario] = stub.NewState
+		}
+
+		return stub, true
+	}
+
+	return Stub{}, false
+}
+
+// NeedsBody returns true if matching the request against the stubs depends
+// on its body.
+func (s *Stubs) NeedsBody(req CapturedRequest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stub := range s.stubs {
+		if stub.Scenario != "" && stub.RequiredState != "" && s.scenarios[stub.Scenario] != stub.RequiredState {
+			continue
+		}
+
+		if stub.Request.NeedsBody(req) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StubsMiddleware returns a middleware serving the response of the first
+// stub matching the request, and falling through to the server's routes when
+// none does. Requests whose path starts with one of the skipped prefixes are
+// never stubbed.
+func StubsMiddleware(stubs *Stubs, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if stubs.Len() == 0 || hasAnyPathPrefix(req.URL.Path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			matched, err := newMatchedRequest(req, stubs.NeedsBody)
+			if err != nil {
+				return err
+			}
+
+			stub, ok := stubs.Match(matched)
+			if !ok {
+				return next(ctx)
+			}
+
+			return stub.Response.Write(ctx)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubs_Match(t *testing.T) {
+	t.Parallel()
+
+	stubs := NewStubs()
+
+	low, err := stubs.Add(Stub{Request: RequestMatcher{Path: "/users/.*"}})
+	require.NoError(t, err)
+	high, err := stubs.Add(Stub{Priority: 10, Request: RequestMatcher{Path: "/users/.*", Method: http.MethodGet}})
+	require.NoError(t, err)
+	recent, err := stubs.Add(Stub{Request: RequestMatcher{Path: "/users/.*", Method: http.MethodDelete}})
+	require.NoError(t, err)
+
+	got, ok := stubs.Match(CapturedRequest{Method: http.MethodGet, Path: "/users/1"})
+	assert.True(t, ok)
+	assert.Equal(t, high.ID, got.ID, "highest priority stub should match first")
+
+	got, ok = stubs.Match(CapturedRequest{Method: http.MethodDelete, Path: "/users/1"})
+	assert.True(t, ok)
+	assert.Equal(t, recent.ID, got.ID, "most recent stub should match first among equal priorities")
+
+	got, ok = stubs.Match(CapturedRequest{Method: http.MethodPost, Path: "/users/1"})
+	assert.True(t, ok)
+	assert.Equal(t, low.ID, got.ID)
+
+	_, ok = stubs.Match(CapturedRequest{Method: http.MethodGet, Path: "/orders/1"})
+	assert.False(t, ok)
+}
+
+func TestStubs_AddCompilesTemplate(t *testing.T) {
+	t.Parallel()
+
+	stubs := NewStubs()
+
+	_, err := stubs.Add(Stub{Response: ResponseDefinition{Body: "{{ .Path", Template: true}})
+	assert.Error(t, err, "template syntax errors should be reported at registration")
+
+	stub, err := stubs.Add(Stub{Response: ResponseDefinition{Body: "{{ .Path }}", Template: true}})
+	require.NoError(t, err)
+
+	got, ok := stubs.Get(stub.ID)
+	require.True(t, ok)
+	assert.NotNil(t, got.Response.template, "registered stubs should hold their parsed template")
+}
+
+func TestStubs_NeedsBody(t *testing.T) {
+	t.Parallel()
+
+	stubs := NewStubs()
+	_, err := stubs.Add(Stub{Request: RequestMatcher{Path: "/users"}})
+	require.NoError(t, err)
+
+	assert.False(t, stubs.NeedsBody(CapturedRequest{Method: http.MethodPost, Path: "/users"}))
+
+	_, err = stubs.Add(Stub{Request: RequestMatcher{Method: http.MethodPost, BodyContains: "alice"}, Scenario: "signup", RequiredState: "Closed"})
+	require.NoError(t, err)
+
+	assert.False(t, stubs.NeedsBody(CapturedRequest{Method: http.MethodPost, Path: "/users"}), "stubs whose scenario is in another state should not need the body")
+
+	stubs.SetScenario("signup", "Closed")
+	assert.True(t, stubs.NeedsBody(CapturedRequest{Method: http.MethodPost, Path: "/users"}))
+	assert.False(t, stubs.NeedsBody(CapturedRequest{Method: http.MethodGet, Path: "/users"}))
+}
+
+func TestStubs_Scenarios(t *testing.T) {
+	t.Parallel()
+
+	stubs := NewStubs()
+
+	for _, stub := range []Stub{
+		{
+			Request:       RequestMatcher{Path: "/order"},
+			Response:      ResponseDefinition{Body: "pending"},
+			Scenario:      "order",
+			RequiredState: ScenarioStarted,
+			NewState:      "shipped",
+		},
+		{
+			Request:       RequestMatcher{Path: "/order"},
+			Response:      ResponseDefinition{Body: "shipped"},
+			Scenario:      "order",
+			RequiredState: "shipped",
+		},
+	} {
+		_, err := stubs.Add(stub)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]string{"order": ScenarioStarted}, stubs.Scenarios())
+
+	for _, want := range []string{"pending", "shipped", "shipped"} {
+		got, ok := stubs.Match(CapturedRequest{Path: "/order"})
+		assert.True(t, ok)
+		assert.Equal(t, want, got.Response.Body)
+	}
+
+	stubs.ResetScenarios()
+	assert.Equal(t, map[string]string{"order": ScenarioStarted}, stubs.Scenarios())
+}
+
+func TestStub_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		stub    Stub
+		wantErr bool
+	}{
+		{
+			name: "valid stub should pass",
+			stub: Stub{Request: RequestMatcher{Path: "/users/[0-9]+"}, Response: ResponseDefinition{Status: http.StatusCreated}},
+		},
+		{
+			name:    "invalid path pattern should fail",
+			stub:    Stub{Request: RequestMatcher{Path: "("}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid status should fail",
+			stub:    Stub{Response: ResponseDefinition{Status: 42}},
+			wantErr: true,
+		},
+		{
+			name:    "states without a scenario should fail",
+			stub:    Stub{NewState: "done"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.stub.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStubsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	stubs := NewStubs()
+	_, err := stubs.Add(Stub{
+		Request: RequestMatcher{Method: http.MethodPost, BodyContains: "stubbed"},
+		Response: ResponseDefinition{
+			Status:      http.StatusTeapot,
+			Headers:     map[string]string{"X-Stubbed": "true"},
+			Body:        `{"stubbed":true}`,
+			ContentType: echo.MIMEApplicationJSON,
+		},
+	})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(StubsMiddleware(stubs, "/admin"))
+	e.POST("/echo", func(ctx echo.Context) error {
+		body, readErr := io.ReadAll(ctx.Request().Body)
+		if readErr != nil {
+			return readErr
+		}
+
+		return ctx.String(http.StatusOK, string(body))
+	})
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "matching request should be stubbed",
+			target:     "/echo",
+			body:       "please be stubbed",
+			wantStatus: http.StatusTeapot,
+			wantBody:   `{"stubbed":true}`,
+		},
+		{
+			name:       "matching request on a missing route should be stubbed",
+			target:     "/missing",
+			body:       "stubbed",
+			wantStatus: http.StatusTeapot,
+			wantBody:   `{"stubbed":true}`,
+		},
+		{
+			name:       "unmatched request should fall through with its body",
+			target:     "/echo",
+			body:       "hello",
+			wantStatus: http.StatusOK,
+			wantBody:   "hello",
+		},
+		{
+			name:       "skipped prefixes should not be stubbed",
+			target:     "/admin/stubs",
+			body:       "stubbed",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TCPProxyDialTimeout is how long the TCP proxy waits for its upstream to
+// accept a connection.
+const TCPProxyDialTimeout = 10 * time.Second
+
+// TCPToxicsMaxDuration is the longest the toxics can delay data, or hold
+// connections open, for.
+const TCPToxicsMaxDuration = time.Hour
+
+// tcpProxyBufferSize is the size of the chunks the TCP proxy forwards data in.
+const tcpProxyBufferSize = 32 * 1024
+
+// ErrTCPProxyDisabled is returned when the TCP proxy mode is disabled.
+var ErrTCPProxyDisabled = errors.New("TCP proxy mode is disabled")
+
+// errTCPProxyClosed is returned by the writes the closing of the proxy interrupts.
+var errTCPProxyClosed = errors.New("TCP proxy closed")
+
+// TCPProxyOptions holds the options of the TCP proxy mode.
+type TCPProxyOptions struct {
+	// Addr is the address the TCP proxy listens on. An empty address
+	// disables the TCP proxy mode.
+	Addr string
+
+	// Upstream is the address of the service the connections are forwarded to.
+	Upstream string
+}
+
+// Enabled returns true if the TCP proxy mode applies.
+func (o TCPProxyOptions) Enabled() bool {
+	return o.Addr != ""
+}
+
+// Validate checks if the TCPProxyOptions struct satisfies the defined constraints.
+func (o TCPProxyOptions) Validate() error {
+	if (o.Addr == "") != (o.Upstream == "") {
+		return errors.New("address and upstream must be set together")
+	}
+
+	return nil
+}
+
+// TCPToxics describes the degradations the TCP proxy applies to the
+// connections it forwards, whatever their protocol.
+type TCPToxics struct {
+	// Upstream applies to the data sent by the clients to the upstream.
+	Upstream TCPDirectionToxics `json:"upstream"`
+
+	// Downstream applies to the data sent by the upstream to the clients.
+	Downstream TCPDirectionToxics `json:"downstream"`
+
+	// ResetRate is the probability, between 0 and 1, for a connection to be
+	// reset, on both of its ends.
+	ResetRate float64 `json:"reset_rate"`
+
+	// ResetAfter is how long reset connections are forwarded for at most,
+	// before being reset. Each connection is reset after a random duration
+	// up to ResetAfter. Zero resets connections as soon as they are accepted.
+	ResetAfter Duration `json:"reset_after"`
+
+	// SlowClose delays forwarding the closing of a connection, by either
+	// of its ends, to the other end.
+	SlowClose Duration `json:"slow_close"`
+}
+
+// TCPDirectionToxics describes the degradations applied to the data flowing
+// through a connection in one direction.
+type TCPDirectionToxics struct {
+	// Latency is added to the time every chunk of data takes to go through.
+	Latency Duration `json:"latency"`
+
+	// Jitter randomly varies the latency of every chunk of data, by up to
+	// its value in either direction.
+	Jitter Duration `json:"jitter"`
+
+	// Bandwidth caps the transfer rate. Zero means unlimited.
+	Bandwidth Bandwidth `json:"bandwidth"`
+}
+
+// Validate checks if the TCPDirectionToxics struct satisfies the defined constraints.
+func (t TCPDirectionToxics) Validate() error {
+	if t.Latency < 0 || t.Jitter < 0 {
+		return errors.New("latency and jitter cannot be negative")
+	}
+
+	if t.Latency > Duration(TCPToxicsMaxDuration) || t.Jitter > Duration(TCPToxicsMaxDuration) {
+		return fmt.Errorf("latency and jitter cannot exceed %s", TCPToxicsMaxDuration)
+	}
+
+	return nil
+}
+
+// Validate checks if the TCPToxics struct satisfies the defined constraints.
+func (t TCPToxics) Validate() error {
+	if err := t.Upstream.Validate(); err != nil {
+		return err
+	}
+
+	if err := t.Downstream.Validate(); err != nil {
+		return err
+	}
+
+	if t.ResetRate < 0 || t.ResetRate > 1 {
+		return errors.New("reset rate must be between 0 and 1")
+	}
+
+	if t.ResetAfter < 0 || t.SlowClose < 0 {
+		return errors.New("reset after and slow close cannot be negative")
+	}
+
+	if t.ResetAfter > Duration(TCPToxicsMaxDuration) || t.SlowClose > Duration(TCPToxicsMaxDuration) {
+		return fmt.Errorf("reset after and slow close cannot exceed %s", TCPToxicsMaxDuration)
+	}
+
+	return nil
+}
+
+// delay returns the latency of a chunk of data, jitter included.
+//
+//nolint:gosec
+func (t TCPDirectionToxics) delay() time.Duration {
+	delay := time.Duration(t.Latency)
+	if t.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(2*int64(t.Jitter)+1)) - time.Duration(t.Jitter)
+	}
+
+	return max(delay, 0)
+}
+
+// write writes the data to the writer, no faster than the bandwidth allows,
+// waiting between writes with sleep.
+func (t TCPDirectionToxics) write(w io.Writer, data []byte, sleep func(time.Duration) bool) error {
+	if t.Bandwidth <= 0 {
+		_, err := w.Write(data)
+		return err
+	}
+
+	pieceSize := max(int(t.Bandwidth)/10, 1)
+	for len(data) > 0 {
+		piece := data[:min(pieceSize, len(data))]
+
+		start := time.Now()
+		if _, err := w.Write(piece); err != nil {
+			return err
+		}
+
+		duration := time.Duration(float64(len(piece)) / float64(t.Bandwidth) * float64(time.Second))
+		if !sleep(time.Until(start.Add(duration))) {
+			return errTCPProxyClosed
+		}
+
+		data = data[len(piece):]
+	}
+
+	return nil
+}
+
+// TCPProxyStatus describes the TCP proxy, and the connections it forwards.
+type TCPProxyStatus struct {
+	// Addr is the address the TCP proxy listens on.
+	Addr string `json:"addr"`
+
+	// Upstream is the address the connections are forwarded to.
+	Upstream string `json:"upstream"`
+
+	// Toxics are the degradations currently applied.
+	Toxics TCPToxics `json:"toxics"`
+
+	// Active is the number of connections currently forwarded.
+	Active int64 `json:"active"`
+
+	// Total is the number of connections accepted since the proxy started.
+	Total int64 `json:"total"`
+}
+
+// TCPProxy forwards TCP connections to an upstream, degrading them as its
+// toxics describe. Changes to the toxics apply to the connections already
+// forwarded, except for resets, which are drawn when connections are accepted.
+//
+// It is safe for concurrent use.
+type TCPProxy struct {
+	options TCPProxyOptions
+
+	mu       sync.RWMutex
+	toxics   TCPToxics
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	done     chan struct{}
+
+	wg     sync.WaitGroup
+	active atomic.Int64
+	total  atomic.Int64
+}
+
+// NewTCPProxy creates a new TCPProxy instance, applying no toxics.
+func NewTCPProxy(options TCPProxyOptions) *TCPProxy {
+	return &TCPProxy{
+		options: options,
+		conns:   make(map[net.Conn]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Toxics returns the toxics currently applied.
+func (p *TCPProxy) Toxics() TCPToxics {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.toxics
+}
+
+// SetToxics validates and applies the toxics.
+func (p *TCPProxy) SetToxics(toxics TCPToxics) error {
+	if err := toxics.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.toxics = toxics
+
+	return nil
+}
+
+// Status returns the status of the TCP proxy.
+func (p *TCPProxy) Status() TCPProxyStatus {
+	return TCPProxyStatus{
+		Addr:     p.options.Addr,
+		Upstream: p.options.Upstream,
+		Toxics:   p.Toxics(),
+		Active:   p.active.Load(),
+		Total:    p.total.Load(),
+	}
+}
+
+// Serve accepts connections on the listener, and forwards them to the
+// upstream, until the proxy is closed.
+func (p *TCPProxy) Serve(listener net.Listener) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return listener.Close()
+	}
+	p.listener = listener
+	p.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !p.track(conn) {
+			_ = conn.Close()
+			return nil
+		}
+
+		p.total.Add(1)
+		p.wg.Add(1)
+		go p.forward(conn)
+	}
+}
+
+// Close stops accepting connections, closes the ones being forwarded, and
+// waits for them to be done with. Data held up by the toxics is dropped.
+// Closing the proxy again is a no-op.
+func (p *TCPProxy) Close() error {
+	p.mu.Lock()
+	var err error
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+
+		if p.listener != nil {
+			err = p.listener.Close()
+		}
+	}
+
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	return err
+}
+
+// track registers the connection for it to be closed along with the proxy,
+// and returns false if the proxy is already closed.
+func (p *TCPProxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+
+	return true
+}
+
+// sleep waits for the duration, and returns false if the proxy is closed in
+// the meantime.
+func (p *TCPProxy) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// untrack forgets about the connection, and closes it.
+func (p *TCPProxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	delete(p.conns, conn)
+	p.mu.Unlock()
+
+	_ = conn.Close()
+}
+
+// forward forwards the client connection to the upstream, until both of them
+// are closed.
+//
+//nolint:gosec
+func (p *TCPProxy) forward(client net.Conn) {
+	defer p.wg.Done()
+	defer p.untrack(client)
+
+	upstream, err := net.DialTimeout("tcp", p.options.Upstream, TCPProxyDialTimeout)
+	if err != nil {
+		slog.Error(
+			"failed connecting to upstream",
+			"handler", "TCPProxy",
+			"upstream", p.options.Upstream,
+			"error_message", err.Error(),
+		)
+
+		return
+	}
+	if !p.track(upstream) {
+		_ = upstream.Close()
+		return
+	}
+	defer p.untrack(upstream)
+
+	p.active.Add(1)
+	defer p.active.Add(-1)
+
+	if toxics := p.Toxics(); rand.Float64() < toxics.ResetRate {
+		var after time.Duration
+		if toxics.ResetAfter > 0 {
+			after = time.Duration(rand.Int63n(int64(toxics.ResetAfter)))
+		}
+
+		timer := time.AfterFunc(after, func() {
+			resetConn(client)
+			resetConn(upstream)
+		})
+		defer timer.Stop()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.pipe(upstream, client, func(t TCPToxics) TCPDirectionToxics { return t.Upstream })
+	}()
+	go func() {
+		defer wg.Done()
+		p.pipe(client, upstream, func(t TCPToxics) TCPDirectionToxics { return t.Downstream })
+	}()
+	wg.Wait()
+}
+
+// tcpChunk is a chunk of data read from one end of a connection, waiting to
+// be written to the other.
+type tcpChunk struct {
+	data []byte
+	read time.Time
+}
+
+// pipe forwards the data read from src to dst, degraded by the toxics the
+// direction selects, until src is closed.
+//
+// Chunks of data are read as soon as they arrive, and written once their
+// latency has passed, so that latency does not hold up the transfer rate.
+// Once src is closed, the closing is forwarded to dst after the slow close
+// delay. Failures, and the closing of the proxy, close both ends.
+func (p *TCPProxy) pipe(dst, src net.Conn, direction func(TCPToxics) TCPDirectionToxics) {
+	chunks := make(chan tcpChunk, 64)
+	go func() {
+		defer close(chunks)
+
+		for {
+			buffer := make([]byte, tcpProxyBufferSize)
+			n, err := src.Read(buffer)
+			if n > 0 {
+				chunks <- tcpChunk{data: buffer[:n], read: time.Now()}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for chunk := range chunks {
+		toxics := direction(p.Toxics())
+		if !p.sleep(time.Until(chunk.read.Add(toxics.delay()))) || toxics.write(dst, chunk.data, p.sleep) != nil {
+			_ = src.Close()
+			_ = dst.Close()
+
+			// Let the reader run into the closed connection
+			for range chunks { //nolint:revive
+			}
+
+			return
+		}
+	}
+
+	if p.sleep(time.Duration(p.Toxics().SlowClose)) {
+		closeWrite(dst)
+	}
+}
+
+// closeWrite closes the writing side of the connection, if it supports it,
+// and the whole connection otherwise.
+func closeWrite(conn net.Conn) {
+	if writeCloser, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = writeCloser.CloseWrite()
+		return
+	}
+
+	_ = conn.Close()
+}
+
+// resetConn closes the connection abruptly, with a TCP reset.
+func resetConn(conn net.Conn) {
+	if tcp, ok := unwrapConn(conn).(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+
+	_ = conn.Close()
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPToxics_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		toxics  TCPToxics
+		wantErr bool
+	}{
+		{"no toxics should be valid", TCPToxics{}, false},
+		{"negative latency should fail", TCPToxics{Upstream: TCPDirectionToxics{Latency: Duration(-time.Second)}}, true},
+		{"negative jitter should fail", TCPToxics{Downstream: TCPDirectionToxics{Jitter: Duration(-time.Second)}}, true},
+		{"reset rate above 1 should fail", TCPToxics{ResetRate: 2}, true},
+		{"negative slow close should fail", TCPToxics{SlowClose: Duration(-time.Second)}, true},
+		{"maximum latency and jitter should be valid", TCPToxics{Upstream: TCPDirectionToxics{Latency: Duration(TCPToxicsMaxDuration), Jitter: Duration(TCPToxicsMaxDuration)}}, false},
+		{"latency above the maximum should fail", TCPToxics{Upstream: TCPDirectionToxics{Latency: Duration(TCPToxicsMaxDuration + 1)}}, true},
+		{"overflowing jitter should fail", TCPToxics{Downstream: TCPDirectionToxics{Jitter: Duration(1 << 62)}}, true},
+		{"slow close above the maximum should fail", TCPToxics{SlowClose: Duration(TCPToxicsMaxDuration + 1)}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantErr, tt.toxics.Validate() != nil)
+		})
+	}
+}
+
+func TestTCPDirectionToxics_delay(t *testing.T) {
+	t.Parallel()
+
+	toxics := TCPDirectionToxics{Latency: Duration(100 * time.Millisecond), Jitter: Duration(20 * time.Millisecond)}
+	jitterOnly := TCPDirectionToxics{Jitter: Duration(time.Second)}
+	for i := 0; i < 100; i++ {
+		delay := toxics.delay()
+		assert.GreaterOrEqual(t, delay, 80*time.Millisecond)
+		assert.LessOrEqual(t, delay, 120*time.Millisecond)
+		assert.GreaterOrEqual(t, jitterOnly.delay(), time.Duration(0), "delay should not be negative")
+	}
+}
+
+// newTCPProxyTestUpstream returns the address of an upstream echoing the
+// data it receives, and closing connections once their client closes them.
+func newTCPProxyTestUpstream(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// newTestTCPProxy returns a TCP proxy forwarding connections to an echoing
+// upstream, with the toxics applied, along with the address it listens on.
+func newTestTCPProxy(t *testing.T, toxics TCPToxics) (*TCPProxy, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	proxy := NewTCPProxy(TCPProxyOptions{Addr: listener.Addr().String(), Upstream: newTCPProxyTestUpstream(t)})
+	require.NoError(t, proxy.SetToxics(toxics))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, proxy.Serve(listener))
+	}()
+
+	t.Cleanup(func() {
+		assert.NoError(t, proxy.Close())
+		<-done
+	})
+
+	return proxy, listener.Addr().String()
+}
+
+func TestTCPProxy(t *testing.T) {
+	t.Parallel()
+
+	proxy, addr := newTestTCPProxy(t, TCPToxics{
+		Upstream:   TCPDirectionToxics{Latency: Duration(30 * time.Millisecond)},
+		Downstream: TCPDirectionToxics{Latency: Duration(30 * time.Millisecond)},
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	start := time.Now()
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	buffer := make([]byte, 5)
+	_, err = io.ReadFull(conn, buffer)
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello", string(buffer))
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond, "latency should apply in both directions")
+
+	status := proxy.Status()
+	assert.Equal(t, int64(1), status.Active)
+	assert.Equal(t, int64(1), status.Total)
+
+	// Closing the client should close the connection to the upstream
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool { return proxy.Status().Active == 0 }, time.Second, time.Millisecond)
+}
+
+func TestTCPProxy_Bandwidth(t *testing.T) {
+	t.Parallel()
+
+	_, addr := newTestTCPProxy(t, TCPToxics{Downstream: TCPDirectionToxics{Bandwidth: 4 * 1024}})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	start := time.Now()
+	_, err = conn.Write(make([]byte, 1024))
+	require.NoError(t, err)
+
+	_, err = io.ReadFull(conn, make([]byte, 1024))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond, "1kb at 4kb/s should take about 250ms")
+}
+
+func TestTCPProxy_Reset(t *testing.T) {
+	t.Parallel()
+
+	_, addr := newTestTCPProxy(t, TCPToxics{ResetRate: 1})
+
+	// The connection may be reset before the dial even returns
+	conn, err := net.Dial("tcp", addr)
+	if err == nil {
+		defer conn.Close()
+		_, err = conn.Read(make([]byte, 1))
+	}
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, io.EOF, "connection should be reset rather than closed")
+}
+
+func TestTCPProxy_SlowClose(t *testing.T) {
+	t.Parallel()
+
+	_, addr := newTestTCPProxy(t, TCPToxics{SlowClose: Duration(50 * time.Millisecond)})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Closing the writing side makes the upstream close the connection,
+	// which is forwarded after the slow close delay, on each way.
+	start := time.Now()
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite()) //nolint:forcetypeassert
+
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestTCPProxy_CloseInterruptsToxics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		toxics TCPToxics
+	}{
+		{"latency should be interrupted", TCPToxics{Upstream: TCPDirectionToxics{Latency: Duration(TCPToxicsMaxDuration)}}},
+		{"bandwidth should be interrupted", TCPToxics{Upstream: TCPDirectionToxics{Bandwidth: 1}}},
+		{"slow close should be interrupted", TCPToxics{SlowClose: Duration(TCPToxicsMaxDuration)}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			proxy, addr := newTestTCPProxy(t, tt.toxics)
+
+			conn, err := net.Dial("tcp", addr)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("hello"))
+			require.NoError(t, err)
+			require.NoError(t, conn.(*net.TCPConn).CloseWrite()) //nolint:forcetypeassert
+			require.Eventually(t, func() bool { return proxy.Status().Active == 1 }, time.Second, time.Millisecond)
+
+			closed := make(chan error, 1)
+			go func() { closed <- proxy.Close() }()
+
+			select {
+			case err := <-closed:
+				assert.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("closing the proxy should not wait for the toxics")
+			}
+		})
+	}
+}
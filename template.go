@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// randomStringAlphabet holds the characters random strings are made of.
+const randomStringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// TemplateData is the data response templates are executed with, describing
+// the request being answered.
+type TemplateData struct {
+	// Method is the method of the request.
+	Method string
+
+	// Path is the path of the request.
+	Path string
+
+	// Params holds the path parameters of the request, by name.
+	Params map[string]string
+
+	// Query holds the first value of each query parameter of the request.
+	Query map[string]string
+
+	// Headers holds the first value of each header of the request, by
+	// canonical name.
+	Headers map[string]string
+
+	// Body is the body of the request.
+	Body string
+
+	// JSON is the body of the request, decoded from JSON. It is nil when
+	// the body is not valid JSON.
+	JSON any
+}
+
+// NewTemplateData creates the template data describing the request of the context.
+//
+// The request body is read, and restored so that it can be read again.
+func NewTemplateData(ctx echo.Context) (TemplateData, error) {
+	req := ctx.Request()
+
+	data := TemplateData{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Params:  make(map[string]string),
+		Query:   make(map[string]string),
+		Headers: make(map[string]string),
+	}
+
+	for i, name := range ctx.ParamNames() {
+		if i < len(ctx.ParamValues()) {
+			data.Params[name] = ctx.ParamValues()[i]
+		}
+	}
+
+	for name, values := range req.URL.Query() {
+		data.Query[name] = values[0]
+	}
+
+	for name, values := range req.Header {
+		data.Headers[name] = values[0]
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return data, fmt.Errorf("failed reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		data.Body = string(body)
+		if json.Unmarshal(body, &data.JSON) != nil {
+			data.JSON = nil
+		}
+	}
+
+	return data, nil
+}
+
+// ParseResponseTemplate parses a response body as a Go text/template.
+//
+// On top of the built-in functions, templates can use:
+//   - uuid, returning a random UUID
+//   - randomInt, returning a random integer between its two arguments, inclusive
+//   - randomString, returning a random alphanumeric string of the given length
+//   - now, returning the current time, which can be formatted with its methods
+//   - json, returning its argument encoded as JSON
+func ParseResponseTemplate(text string) (*template.Template, error) {
+	return template.New("response").Funcs(templateFuncs()).Parse(text)
+}
+
+// ExecuteResponseTemplate renders a response body template for the request of the context.
+func ExecuteResponseTemplate(ctx echo.Context, tmpl *template.Template) ([]byte, error) {
+	data, err := NewTemplateData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	if err = tmpl.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed rendering template: %w", err)
+	}
+
+	return body.Bytes(), nil
+}
+
+// templateFuncs returns the functions available to response templates.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"uuid": func() string {
+			return uuid.NewString()
+		},
+		"randomInt": func(lo, hi int) (int, error) {
+			if hi < lo {
+				return 0, fmt.Errorf("randomInt: %d is lower than %d", hi, lo)
+			}
+			return lo + rand.Intn(hi-lo+1), nil //nolint:gosec
+		},
+		"randomString": func(length int) string {
+			var s strings.Builder
+			for i := 0; i < length; i++ {
+				s.WriteByte(randomStringAlphabet[rand.Intn(len(randomStringAlphabet))]) //nolint:gosec
+			}
+			return s.String()
+		},
+		"now": time.Now,
+		"json": func(v any) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}
+}
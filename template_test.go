@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTemplateData(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42?token=abc&token=def", strings.NewReader(`{"user":{"id":7}}`))
+	req.Header.Set("x-request-id", "r1")
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("42")
+
+	data, err := NewTemplateData(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, data.Method)
+	assert.Equal(t, "/users/42", data.Path)
+	assert.Equal(t, map[string]string{"id": "42"}, data.Params)
+	assert.Equal(t, map[string]string{"token": "abc"}, data.Query)
+	assert.Equal(t, "r1", data.Headers["X-Request-Id"])
+	assert.Equal(t, `{"user":{"id":7}}`, data.Body)
+	assert.Equal(t, map[string]any{"user": map[string]any{"id": float64(7)}}, data.JSON)
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user":{"id":7}}`, string(body), "request body should be restored")
+}
+
+func TestExecuteResponseTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		template  string
+		body      string
+		want      string
+		wantMatch *regexp.Regexp
+		wantErr   bool
+	}{
+		{
+			name:     "request data should be accessible",
+			template: `{{ .Method }} {{ .Path }} {{ .Query.token }} {{ .JSON.user.id }}`,
+			body:     `{"user":{"id":7}}`,
+			want:     "POST /users abc 7",
+		},
+		{
+			name:     "json should encode its argument",
+			template: `{{ json .JSON }}`,
+			body:     `{"user":{"id":7}}`,
+			want:     `{"user":{"id":7}}`,
+		},
+		{
+			name:      "uuid should generate a uuid",
+			template:  `{{ uuid }}`,
+			wantMatch: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+		},
+		{
+			name:     "randomInt should generate an integer within bounds",
+			template: `{{ randomInt 3 3 }}`,
+			want:     "3",
+		},
+		{
+			name:      "randomString should generate a string of the length",
+			template:  `{{ randomString 12 }}`,
+			wantMatch: regexp.MustCompile(`^[a-zA-Z0-9]{12}$`),
+		},
+		{
+			name:      "now should return the current time",
+			template:  `{{ now.Year }}`,
+			wantMatch: regexp.MustCompile(`^\d{4}$`),
+		},
+		{
+			name:     "invalid randomInt bounds should fail",
+			template: `{{ randomInt 3 1 }}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := ParseResponseTemplate(tt.template)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/users?token=abc", strings.NewReader(tt.body))
+			ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+			got, err := ExecuteResponseTemplate(ctx, tmpl)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.wantMatch != nil {
+				assert.Regexp(t, tt.wantMatch, string(got))
+			} else {
+				assert.Equal(t, tt.want, string(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderQueueWait is the response header holding the time, in milliseconds,
+// the request waited in the worker pool's queue before being served.
+const HeaderQueueWait = "X-Lhotse-Queue-Wait"
+
+// DefaultRejectStatus is the status of the requests rejected by the worker
+// pool when none is specified.
+const DefaultRejectStatus = http.StatusServiceUnavailable
+
+// WorkerPoolOptions describes a server serving a bounded number of requests
+// at once, and queueing a bounded number of the others.
+type WorkerPoolOptions struct {
+	// Workers is the number of requests served at once. Zero disables the
+	// worker pool.
+	Workers int
+
+	// QueueDepth is the number of requests waiting for a worker at most.
+	// Requests arriving while the queue is full are rejected. Zero means
+	// requests are rejected as soon as every worker is busy.
+	QueueDepth int
+
+	// QueueTimeout is how long a request waits for a worker at most, before
+	// being rejected. Zero means requests wait as long as needed.
+	QueueTimeout time.Duration
+
+	// RejectStatus is the status of the rejected requests.
+	RejectStatus int
+
+	// Drop closes the connection of the rejected requests without
+	// responding, rather than responding with RejectStatus.
+	Drop bool
+}
+
+// DefaultWorkerPoolOptions returns the default worker pool options.
+func DefaultWorkerPoolOptions() WorkerPoolOptions {
+	return WorkerPoolOptions{RejectStatus: DefaultRejectStatus}
+}
+
+// Enabled returns true if the worker pool applies.
+func (o WorkerPoolOptions) Enabled() bool {
+	return o.Workers > 0
+}
+
+// Validate checks if the WorkerPoolOptions struct satisfies the defined constraints.
+func (o WorkerPoolOptions) Validate() error {
+	if o.Workers < 0 {
+		return errors.New("workers cannot be negative")
+	}
+
+	if o.QueueDepth < 0 {
+		return errors.New("queue depth cannot be negative")
+	}
+
+	if o.QueueTimeout < 0 {
+		return errors.New("queue timeout cannot be negative")
+	}
+
+	if o.RejectStatus < 100 || o.RejectStatus > 599 {
+		return errors.New("reject status must be between 100 and 599")
+	}
+
+	return nil
+}
+
+// WorkerPool limits the number of requests served at once, queueing or
+// rejecting the others.
+//
+// Its activity is reported through the following metrics:
+//   - workers.busy and workers.queued, the number of busy workers and
+//     queued requests
+//   - workers.served, workers.rejected and workers.dropped, the number of
+//     requests served, rejected and dropped
+//   - workers.queue_wait_ms, the total time served requests waited in the
+//     queue
+//
+// It is safe for concurrent use.
+type WorkerPool struct {
+	options WorkerPoolOptions
+	metrics *Metrics
+
+	workers chan struct{}
+	queued  atomic.Int64
+}
+
+// NewWorkerPool creates a new WorkerPool instance, reporting to the provided metrics.
+func NewWorkerPool(options WorkerPoolOptions, metrics *Metrics) *WorkerPool {
+	return &WorkerPool{
+		options: options,
+		metrics: metrics,
+		workers: make(chan struct{}, options.Workers),
+	}
+}
+
+// acquire waits for a free worker, and returns how long it waited, and
+// false if the request was rejected.
+func (p *WorkerPool) acquire(done <-chan struct{}) (time.Duration, bool) {
+	// Serve the request right away if a worker is free
+	select {
+	case p.workers <- struct{}{}:
+		p.metrics.Set("workers.busy", int64(len(p.workers)))
+		return 0, true
+	default:
+	}
+
+	// Otherwise queue it, if there is room for it
+	queued := p.queued.Add(1)
+	defer func() {
+		p.metrics.Set("workers.queued", p.queued.Add(-1))
+	}()
+
+	if queued > int64(p.options.QueueDepth) {
+		return 0, false
+	}
+	p.metrics.Set("workers.queued", queued)
+
+	var timeout <-chan time.Time
+	if p.options.QueueTimeout > 0 {
+		timer := time.NewTimer(p.options.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	start := time.Now()
+	select {
+	case p.workers <- struct{}{}:
+		p.metrics.Set("workers.busy", int64(len(p.workers)))
+		return time.Since(start), true
+	case <-timeout:
+		return time.Since(start), false
+	case <-done:
+		return time.Since(start), false
+	}
+}
+
+// release frees a worker.
+func (p *WorkerPool) release() {
+	<-p.workers
+	p.metrics.Set("workers.busy", int64(len(p.workers)))
+}
+
+// WorkerPoolMiddleware returns a middleware serving every request whose path
+// does not start with one of the skipped prefixes through the worker pool.
+//
+// The time requests waited in the queue is reported in the
+// X-Lhotse-Queue-Wait response header.
+func WorkerPoolMiddleware(pool *WorkerPool, skippedPrefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if hasAnyPathPrefix(ctx.Request().URL.Path, skippedPrefixes) {
+				return next(ctx)
+			}
+
+			wait, ok := pool.acquire(ctx.Request().Context().Done())
+			ctx.Response().Header().Set(HeaderQueueWait, strconv.FormatFloat(milliseconds(wait), 'f', 3, 64))
+			if !ok {
+				return pool.reject(ctx)
+			}
+			defer pool.release()
+
+			pool.metrics.Add("workers.served", 1)
+			pool.metrics.Add("workers.queue_wait_ms", wait.Milliseconds())
+
+			return next(ctx)
+		}
+	}
+}
+
+// reject answers a request the worker pool could not serve, either by
+// dropping its connection, or by responding with the rejection status. Requests
+// whose connection cannot be dropped, such as HTTP/2 ones, are responded to.
+func (p *WorkerPool) reject(ctx echo.Context) error {
+	if p.options.Drop {
+		if conn, _, err := http.NewResponseController(ctx.Response().Writer).Hijack(); err == nil {
+			p.metrics.Add("workers.dropped", 1)
+			return conn.Close()
+		}
+	}
+
+	p.metrics.Add("workers.rejected", 1)
+
+	return ctx.String(p.options.RejectStatus, "server overloaded")
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPoolOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options WorkerPoolOptions
+		wantErr bool
+	}{
+		{"default options should be valid", DefaultWorkerPoolOptions(), false},
+		{"negative workers should fail", WorkerPoolOptions{Workers: -1, RejectStatus: DefaultRejectStatus}, true},
+		{"negative queue depth should fail", WorkerPoolOptions{QueueDepth: -1, RejectStatus: DefaultRejectStatus}, true},
+		{"negative queue timeout should fail", WorkerPoolOptions{QueueTimeout: -time.Second, RejectStatus: DefaultRejectStatus}, true},
+		{"invalid reject status should fail", WorkerPoolOptions{RejectStatus: 600}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantErr, tt.options.Validate() != nil)
+		})
+	}
+}
+
+// newWorkerPoolTestServer returns an Echo instance serving /block through
+// the worker pool, until release is closed.
+func newWorkerPoolTestServer(pool *WorkerPool, release chan struct{}) *echo.Echo {
+	e := echo.New()
+	e.Use(WorkerPoolMiddleware(pool, DefaultAdminPrefix))
+	e.GET("/block", func(ctx echo.Context) error {
+		<-release
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	return e
+}
+
+func TestWorkerPoolMiddleware(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewMetrics()
+	pool := NewWorkerPool(WorkerPoolOptions{Workers: 1, QueueDepth: 1, RejectStatus: DefaultRejectStatus}, metrics)
+	release := make(chan struct{})
+	e := newWorkerPoolTestServer(pool, release)
+
+	// serve serves a request in the background, and returns its recorder
+	var wg sync.WaitGroup
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block", nil))
+		}()
+		return rec
+	}
+
+	served := serve()
+	require.Eventually(t, func() bool { n, _ := metrics.Get("workers.busy"); return n == 1 }, time.Second, time.Millisecond)
+
+	queued := serve()
+	require.Eventually(t, func() bool { n, _ := metrics.Get("workers.queued"); return n == 1 }, time.Second, time.Millisecond)
+
+	// Requests beyond the queue depth should be rejected right away
+	rejected := httptest.NewRecorder()
+	e.ServeHTTP(rejected, httptest.NewRequest(http.MethodGet, "/block", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.Code)
+	assert.Equal(t, "0.000", rejected.Header().Get(HeaderQueueWait))
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusOK, served.Code)
+	assert.Equal(t, "0.000", served.Header().Get(HeaderQueueWait))
+	assert.Equal(t, http.StatusOK, queued.Code)
+	wait, err := strconv.ParseFloat(queued.Header().Get(HeaderQueueWait), 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, wait, 10.0, "queued request should report its wait")
+
+	for name, want := range map[string]int64{"workers.served": 2, "workers.rejected": 1, "workers.busy": 0, "workers.queued": 0} {
+		got, _ := metrics.Get(name)
+		assert.Equal(t, want, got, name)
+	}
+}
+
+func TestWorkerPoolMiddleware_QueueTimeout(t *testing.T) {
+	t.Parallel()
+
+	pool := NewWorkerPool(WorkerPoolOptions{Workers: 1, QueueDepth: 1, QueueTimeout: 20 * time.Millisecond, RejectStatus: http.StatusTooManyRequests}, NewMetrics())
+	release := make(chan struct{})
+	defer close(release)
+	e := newWorkerPoolTestServer(pool, release)
+
+	go e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/block", nil))
+	require.Eventually(t, func() bool { return len(pool.workers) == 1 }, time.Second, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	wait, err := strconv.ParseFloat(rec.Header().Get(HeaderQueueWait), 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, wait, 20.0)
+}
+
+func TestWorkerPoolMiddleware_Drop(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewMetrics()
+	pool := NewWorkerPool(WorkerPoolOptions{Workers: 1, RejectStatus: DefaultRejectStatus, Drop: true}, metrics)
+	release := make(chan struct{})
+	server := httptest.NewServer(newWorkerPoolTestServer(pool, release))
+	defer server.Close()
+	defer close(release)
+
+	go func() {
+		if res, err := http.Get(server.URL + "/block"); err == nil { //nolint:noctx
+			res.Body.Close()
+		}
+	}()
+	require.Eventually(t, func() bool { return len(pool.workers) == 1 }, time.Second, time.Millisecond)
+
+	res, err := http.Get(server.URL + "/block") //nolint:noctx
+	if err == nil {
+		res.Body.Close()
+	}
+	assert.Error(t, err, "dropped request should not be responded to")
+
+	dropped, _ := metrics.Get("workers.dropped")
+	assert.Equal(t, int64(1), dropped)
+}